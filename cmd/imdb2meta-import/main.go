@@ -6,16 +6,23 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dgraph-io/badger/v2"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
 	"go.etcd.io/bbolt"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/index"
 	"github.com/deflix-tv/imdb2meta/pb"
+	"github.com/deflix-tv/imdb2meta/staticdb"
 )
 
 var (
@@ -23,6 +30,13 @@ var (
 
 	badgerPath = flag.String("badgerPath", "", "Path to the directory with the BadgerDB files")
 	boltPath   = flag.String("boltPath", "", "Path to the bbolt DB file")
+	staticPath = flag.String("staticPath", "", "Path to the static DB file. It's an immutable format for read-only serving, so the file is always created from scratch.")
+
+	keyEncoding = flag.String("keyEncoding", "string", `Encoding of the IMDb IDs as DB keys. "string" stores them as is. "numeric" stores the numeric part of the ID as fixed-width integer, which shrinks the DB and makes the keys sort in numeric order. Must be the same for all imports into the same DB.`)
+	compress    = flag.Bool("compress", false, "Compress the values with zstd, using a dictionary that's trained on a sample of the data and stored in the DB. Sampling the data for the training requires reading the whole TSV file an additional time before the import. When importing into an existing DB with compressed values, its dictionary is reused.")
+	indexPath   = flag.String("indexPath", "", "Path to the title index file for the service's search. The index is always created from scratch, from all processed rows of the TSV file.")
+	ratingsPath = flag.String("ratingsPath", "", `Path to the "data.tsv" file that's inside the "title.ratings.tsv.gz" archive. Optional, only used with "-indexPath" for ranking titles by their number of votes.`)
+	datasetDate = flag.String("datasetDate", "", `Date of the IMDb dataset in the format "2006-01-02", stored in the static DB file. Defaults to the modification date of the TSV file.`)
 
 	limit = flag.Int("limit", 0, "Limit the number of rows to process (excluding the header row)")
 
@@ -32,10 +46,23 @@ var (
 )
 
 var (
-	imdbBytes       = []byte("imdb") // Bucket name for bbolt
+	imdbBytes       = []byte("imdb")     // Bucket name for bbolt
+	metadataBytes   = []byte("metadata") // Bucket name for metadata in bbolt
+	metadataPrefix  = "metadata:"        // Key prefix for metadata in BadgerDB
+	keyEncodingKey  = "keyEncoding"      // Metadata key for the key encoding
+	zstdDictKey     = "zstdDict"         // Metadata key for the zstd dictionary
+	zstdMagic       = []byte{0x28, 0xb5, 0x2f, 0xfd}
 	expectedColumns = 9
 )
 
+const (
+	// Number of marshalled Meta objects to train the zstd dictionary on.
+	// The training time grows linearly with the number of samples, with 5000 samples it takes about half a minute.
+	dictSampleSize = 5000
+	// Bigger dictionaries barely improve the compression ratio, but make compressing each value slower
+	maxDictSize = 16 << 10
+)
+
 func main() {
 	// Workaround for exiting with 1 despite not using log.Fatal while still running deferred DB close calls.
 	exitCode := 1
@@ -49,10 +76,25 @@ func main() {
 	if *tsvPath == "" {
 		log.Fatalln(`Missing CLI argument "-tsvPath"`)
 	}
-	if *badgerPath == "" && *boltPath == "" {
-		log.Fatalln(`Missing an argument for the DB: Either "-badgerPath" or "-boltPath".`)
-	} else if *badgerPath != "" && *boltPath != "" {
-		log.Fatalln(`You can only use either "-badgerPath" or "-boltPath", but not both at the same time`)
+	dbArgs := 0
+	for _, dbPath := range []string{*badgerPath, *boltPath, *staticPath} {
+		if dbPath != "" {
+			dbArgs++
+		}
+	}
+	if dbArgs == 0 {
+		log.Fatalln(`Missing an argument for the DB: Either "-badgerPath", "-boltPath" or "-staticPath".`)
+	} else if dbArgs > 1 {
+		log.Fatalln(`You can only use one of "-badgerPath", "-boltPath" and "-staticPath", but not multiple at the same time`)
+	}
+
+	if *ratingsPath != "" && *indexPath == "" {
+		log.Fatalln(`"-ratingsPath" can only be used together with "-indexPath"`)
+	}
+
+	keyEnc, err := imdbid.ParseKeyEncoding(*keyEncoding)
+	if err != nil {
+		log.Fatalf("Invalid key encoding: %v\n", err)
 	}
 
 	f, err := os.Open(*tsvPath)
@@ -60,6 +102,21 @@ func main() {
 		log.Fatalf("Couldn't open TSV file: %v\n", err)
 	}
 
+	var dataDate time.Time
+	if *datasetDate != "" {
+		dataDate, err = time.Parse("2006-01-02", *datasetDate)
+		if err != nil {
+			log.Fatalf("Couldn't parse dataset date: %v\n", err)
+		}
+	} else {
+		fi, err := f.Stat()
+		if err != nil {
+			log.Fatalf("Couldn't get TSV file info: %v\n", err)
+		}
+		modTime := fi.ModTime().UTC()
+		dataDate = time.Date(modTime.Year(), modTime.Month(), modTime.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
 	s := bufio.NewScanner(f)
 
 	i := 1
@@ -70,7 +127,11 @@ func main() {
 
 	var badgerDB *badger.DB
 	var boltDB *bbolt.DB
-	if *badgerPath != "" {
+	var staticWriter *staticdb.Writer
+	if *staticPath != "" {
+		// The static DB is only written after all rows are processed
+		staticWriter = staticdb.NewWriter()
+	} else if *badgerPath != "" {
 		opts := badger.DefaultOptions(*badgerPath).
 			WithLoggingLevel(badger.WARNING).
 			WithSyncWrites(false)
@@ -86,21 +147,127 @@ func main() {
 		}
 		defer boltDB.Close()
 		err = boltDB.Update(func(tx *bbolt.Tx) error {
-			if tx.Bucket(imdbBytes) == nil {
-				_, err := tx.CreateBucket(imdbBytes)
+			if _, err := tx.CreateBucketIfNotExists(imdbBytes); err != nil {
 				return err
 			}
-			return nil
+			_, err := tx.CreateBucketIfNotExists(metadataBytes)
+			return err
 		})
 		if err != nil {
-			log.Fatalf("Couldn't create bucket in bbolt: %v\n", err)
+			log.Fatalf("Couldn't create buckets in bbolt: %v\n", err)
 		}
 	}
 
 	// Here after we have opened the DB, don't use log.Fatal or os.Exit, as then the DB won't be closed and can end up in a corrupted state.
 	// So we log with Print and then return, leading to the deferred DB close and then deferred os.Exit(1) being called.
 
+	// The service needs to know how the keys are encoded
+	if *staticPath != "" {
+		staticWriter.SetMetadata(keyEncodingKey, []byte(keyEnc))
+	} else {
+		storedKeyEnc, err := getMetadata(badgerDB, boltDB, keyEncodingKey)
+		if err != nil {
+			log.Printf("Couldn't read key encoding from DB: %v\n", err)
+			return
+		}
+		if storedKeyEnc == nil {
+			// DBs from older importer versions don't have the metadata, but always use the string encoding
+			empty, err := isEmpty(badgerDB, boltDB)
+			if err != nil {
+				log.Printf("Couldn't check if DB is empty: %v\n", err)
+				return
+			}
+			if !empty {
+				storedKeyEnc = []byte(imdbid.KeyEncodingString)
+			} else if err = setMetadata(badgerDB, boltDB, keyEncodingKey, []byte(keyEnc)); err != nil {
+				log.Printf("Couldn't write key encoding to DB: %v\n", err)
+				return
+			}
+		}
+		if storedKeyEnc != nil && string(storedKeyEnc) != string(keyEnc) {
+			log.Printf("The DB already contains keys with the %q encoding, which can't be mixed with the %q encoding\n", storedKeyEnc, keyEnc)
+			return
+		}
+	}
+
+	// Compression
+	var zstdDict []byte
+	if *staticPath == "" {
+		zstdDict, err = getMetadata(badgerDB, boltDB, zstdDictKey)
+		if err != nil {
+			log.Printf("Couldn't read zstd dictionary from DB: %v\n", err)
+			return
+		}
+	}
+	if zstdDict != nil {
+		// Values that are already in the DB can only be decompressed with the same dictionary
+		log.Println("The values in the DB are compressed, reusing the existing zstd dictionary")
+	} else if *compress {
+		if *staticPath == "" {
+			empty, err := isEmpty(badgerDB, boltDB)
+			if err != nil {
+				log.Printf("Couldn't check if DB is empty: %v\n", err)
+				return
+			}
+			if !empty {
+				log.Println("The DB already contains uncompressed values, which can't be mixed with compressed values")
+				return
+			}
+		}
+		log.Println("Training zstd dictionary...")
+		dictStart := time.Now()
+		samples, err := sampleValues(*tsvPath, dictSampleSize)
+		if err != nil {
+			log.Printf("Couldn't sample values for the zstd dictionary: %v\n", err)
+			return
+		}
+		zstdDict, err = dict.BuildZstdDict(samples, dict.Options{
+			MaxDictSize: maxDictSize,
+			HashBytes:   8,
+			// IDs below 256 only take 1 byte in each compressed value instead of 4
+			ZstdDictID: 1,
+		})
+		if err != nil {
+			log.Printf("Couldn't train zstd dictionary: %v\n", err)
+			return
+		}
+		log.Printf("Trained zstd dictionary with a size of %v bytes on %v samples in %v\n", len(zstdDict), len(samples), time.Since(dictStart))
+		if *staticPath != "" {
+			staticWriter.SetMetadata(zstdDictKey, zstdDict)
+		} else if err = setMetadata(badgerDB, boltDB, zstdDictKey, zstdDict); err != nil {
+			log.Printf("Couldn't write zstd dictionary to DB: %v\n", err)
+			return
+		}
+	}
+	var encoder *zstd.Encoder
+	if zstdDict != nil {
+		// The values are only a few dozen bytes, so the 4 byte checksum per value would be a significant overhead
+		encoder, err = zstd.NewWriter(nil, zstd.WithEncoderDict(zstdDict), zstd.WithEncoderConcurrency(1), zstd.WithEncoderCRC(false))
+		if err != nil {
+			log.Printf("Couldn't create zstd encoder: %v\n", err)
+			return
+		}
+		defer encoder.Close()
+	}
+
+	var indexBuilder *index.Builder
+	var votes map[uint32]uint32
+	if *indexPath != "" {
+		indexBuilder = index.NewBuilder()
+		if *ratingsPath != "" {
+			log.Println("Reading ratings...")
+			votes, err = readVotes(*ratingsPath)
+			if err != nil {
+				log.Printf("Couldn't read ratings: %v\n", err)
+				return
+			}
+			log.Printf("Read votes of %v titles\n", len(votes))
+		}
+	}
+
 	storedCount := 0
+	// Sizes of the values of all processed objects, to see the effect of the compression
+	valuesSize, compressedSize := 0, 0
 	start := time.Now()
 	for ; *limit == 0 || i <= *limit; i++ {
 		if !s.Scan() {
@@ -111,29 +278,21 @@ func main() {
 			return
 		}
 
-		record := strings.Split(s.Text(), "\t")
-		if len(record) != expectedColumns {
-			log.Printf("The row didn't have the expected number of columns (row %v): %#v\n", i, record)
-			return
-		}
-		m, err := toMeta(record, *minimal)
+		m, err := readMeta(s.Text())
 		if err != nil {
-			log.Printf("Couldn't create Meta from record at row %v: %#v: %v\n", i, record, err)
+			log.Printf("Couldn't read row %v: %v\n", i, err)
 			return
-		}
-
-		// Skip all episodes if configured
-		if *skipEpisodes &&
-			(m.GetTitleType() == pb.TitleType_TV_EPISODE ||
-				m.GetTitleType() == pb.TitleType_EPISODE) {
+		} else if m == nil {
 			continue
 		}
-		// Skip other stuff if configured
-		if *skipMisc &&
-			(m.GetTitleType() == pb.TitleType_VIDEO_GAME ||
-				m.GetTitleType() == pb.TitleType_AUDIOBOOK ||
-				m.GetTitleType() == pb.TitleType_RADIO_SERIES) {
-			continue
+
+		if indexBuilder != nil {
+			// The ID is validated by Add. A nil map returns 0 votes for all titles.
+			_, number, _ := imdbid.Parse(m.GetId())
+			if err = indexBuilder.Add(m, votes[number]); err != nil {
+				log.Printf("Couldn't add Meta to title index at row %v: %+v: %v\n", i, m, err)
+				return
+			}
 		}
 
 		mBytes, err := proto.Marshal(m)
@@ -141,12 +300,26 @@ func main() {
 			log.Printf("Couldn't marshal Meta to protocol buffer at row %v: %+v: %v\n", i, m, err)
 			return
 		}
+		valuesSize += len(mBytes)
+		if encoder != nil {
+			// The zstd magic number is the same for all values, so we don't store it. The service adds it again before decompressing.
+			mBytes = encoder.EncodeAll(mBytes, nil)[len(zstdMagic):]
+		}
+		compressedSize += len(mBytes)
+		key, err := keyEnc.Key(m.GetId())
+		if err != nil {
+			log.Printf("Couldn't encode ID at row %v: %v: %v\n", i, m.GetId(), err)
+			return
+		}
 
-		if *badgerPath != "" {
+		if *staticPath != "" {
+			staticWriter.Add(string(key), mBytes)
+			storedCount++
+		} else if *badgerPath != "" {
 			requiresUpdate := false
 			_ = badgerDB.View(func(txn *badger.Txn) error {
 				// err can be badger.ErrKeyNotFound and other errors. In any case we want to write to the DB.
-				item, err := txn.Get([]byte(m.GetId()))
+				item, err := txn.Get(key)
 				if err != nil {
 					requiresUpdate = true
 					return nil
@@ -163,13 +336,13 @@ func main() {
 			if requiresUpdate {
 				err = badgerDB.Update(func(txn *badger.Txn) error {
 					storedCount++
-					return txn.Set([]byte(m.GetId()), mBytes)
+					return txn.Set(key, mBytes)
 				})
 			}
 		} else {
 			requiresUpdate := false
 			_ = boltDB.View(func(tx *bbolt.Tx) error {
-				txBytes := tx.Bucket(imdbBytes).Get([]byte(m.GetId()))
+				txBytes := tx.Bucket(imdbBytes).Get(key)
 				if txBytes == nil {
 					requiresUpdate = true
 					return nil
@@ -183,7 +356,7 @@ func main() {
 			if requiresUpdate {
 				err = boltDB.Update(func(tx *bbolt.Tx) error {
 					storedCount++
-					return tx.Bucket(imdbBytes).Put([]byte(m.GetId()), mBytes)
+					return tx.Bucket(imdbBytes).Put(key, mBytes)
 				})
 			}
 		}
@@ -197,12 +370,141 @@ func main() {
 			log.Printf("Processed %v rows, stored %v objects\n", i+1, storedCount)
 		}
 	}
+	if *staticPath != "" {
+		log.Println("Writing static DB file...")
+		err = staticWriter.WriteFile(*staticPath, dataDate)
+		if err != nil {
+			log.Printf("Couldn't write static DB file: %v\n", err)
+			return
+		}
+	}
+	if indexBuilder != nil {
+		log.Println("Writing title index file...")
+		err = indexBuilder.WriteFile(*indexPath)
+		if err != nil {
+			log.Printf("Couldn't write title index file: %v\n", err)
+			return
+		}
+	}
 	end := time.Now()
 	log.Printf("Processing finished. Processed %v rows, stored %v objects.\n", i, storedCount)
 	log.Printf("Processing took %v\n", end.Sub(start))
+
+	// The DB is closed before measuring its size, so that all data is written to disk. The deferred Close calls don't do anything then.
+	dbPath := *staticPath
+	if badgerDB != nil {
+		dbPath = *badgerPath
+		err = badgerDB.Close()
+	} else if boltDB != nil {
+		dbPath = *boltPath
+		err = boltDB.Close()
+	}
+	if err != nil {
+		log.Printf("Couldn't close DB: %v\n", err)
+		return
+	}
+	dbSize, err := diskSize(dbPath)
+	if err != nil {
+		log.Printf("Couldn't determine DB size: %v\n", err)
+		return
+	}
+	log.Printf("DB size: %.1f MB\n", float64(dbSize)/1e6)
+	if encoder != nil && valuesSize > 0 {
+		log.Printf("Size of all processed values: %.1f MB uncompressed, %.1f MB compressed (%.1f%%)\n",
+			float64(valuesSize)/1e6, float64(compressedSize)/1e6, 100*float64(compressedSize)/float64(valuesSize))
+		// Only the values differ between a DB with and without compression
+		log.Printf("DB size without compression: about %.1f MB\n", float64(dbSize-int64(compressedSize)+int64(valuesSize))/1e6)
+	}
 	exitCode = 0
 }
 
+// readMeta converts a TSV row into a Meta object.
+// Returns nil if the row should be skipped according to the CLI arguments.
+func readMeta(row string) (*pb.Meta, error) {
+	record := strings.Split(row, "\t")
+	if len(record) != expectedColumns {
+		return nil, fmt.Errorf("the row didn't have the expected number of columns: %#v", record)
+	}
+	m, err := toMeta(record, *minimal)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create Meta from record %#v: %v", record, err)
+	}
+
+	// Skip all episodes if configured
+	if *skipEpisodes &&
+		(m.GetTitleType() == pb.TitleType_TV_EPISODE ||
+			m.GetTitleType() == pb.TitleType_EPISODE) {
+		return nil, nil
+	}
+	// Skip other stuff if configured
+	if *skipMisc &&
+		(m.GetTitleType() == pb.TitleType_VIDEO_GAME ||
+			m.GetTitleType() == pb.TitleType_AUDIOBOOK ||
+			m.GetTitleType() == pb.TitleType_RADIO_SERIES) {
+		return nil, nil
+	}
+
+	return m, nil
+}
+
+// sampleValues reads all rows of the TSV file and returns a random sample of the marshalled Meta objects.
+// The whole file is read instead of only the first rows, because the rows are sorted by ID, so the first rows only contain old titles.
+func sampleValues(path string, sampleSize int) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	// Skip the header
+	s.Scan()
+
+	// Reservoir sampling, with a fixed seed for reproducible dictionaries
+	rnd := rand.New(rand.NewSource(1))
+	samples := make([][]byte, 0, sampleSize)
+	seen := 0
+	for i := 1; (*limit == 0 || i <= *limit) && s.Scan(); i++ {
+		m, err := readMeta(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read row %v: %v", i, err)
+		} else if m == nil {
+			continue
+		}
+		seen++
+		j := seen - 1
+		if len(samples) == sampleSize {
+			if j = rnd.Intn(seen); j >= sampleSize {
+				continue
+			}
+		}
+		mBytes, err := proto.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		if j < len(samples) {
+			samples[j] = mBytes
+		} else {
+			samples = append(samples, mBytes)
+		}
+	}
+	return samples, s.Err()
+}
+
+// diskSize returns the size of the file at the given path, or the total size of all files in it for directories like the BadgerDB one.
+func diskSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
 // toMeta converts a TSV record into a Meta object.
 func toMeta(record []string, minimal bool) (*pb.Meta, error) {
 	meta := &pb.Meta{}
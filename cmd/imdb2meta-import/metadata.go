@@ -0,0 +1,77 @@
+package main
+
+import (
+	"github.com/dgraph-io/badger/v2"
+	"go.etcd.io/bbolt"
+)
+
+// getMetadata reads a metadata value from the DB. One of the DBs must be non-nil.
+// Returns nil if the key doesn't exist.
+func getMetadata(badgerDB *badger.DB, boltDB *bbolt.DB, key string) ([]byte, error) {
+	var value []byte
+	var err error
+	if badgerDB != nil {
+		err = badgerDB.View(func(txn *badger.Txn) error {
+			item, err := txn.Get([]byte(metadataPrefix + key))
+			if err == badger.ErrKeyNotFound {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			value, err = item.ValueCopy(nil)
+			return err
+		})
+	} else {
+		err = boltDB.View(func(tx *bbolt.Tx) error {
+			if txBytes := tx.Bucket(metadataBytes).Get([]byte(key)); txBytes != nil {
+				value = append([]byte(nil), txBytes...)
+			}
+			return nil
+		})
+	}
+	return value, err
+}
+
+// setMetadata writes a metadata value to the DB. One of the DBs must be non-nil.
+func setMetadata(badgerDB *badger.DB, boltDB *bbolt.DB, key string, value []byte) error {
+	if badgerDB != nil {
+		return badgerDB.Update(func(txn *badger.Txn) error {
+			return txn.Set([]byte(metadataPrefix+key), value)
+		})
+	}
+	return boltDB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(metadataBytes).Put([]byte(key), value)
+	})
+}
+
+// isEmpty checks if the DB contains any objects. One of the DBs must be non-nil.
+func isEmpty(badgerDB *badger.DB, boltDB *bbolt.DB) (bool, error) {
+	empty := true
+	var err error
+	if badgerDB != nil {
+		err = badgerDB.View(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchValues = false
+			it := txn.NewIterator(opts)
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				if !hasMetadataPrefix(it.Item().Key()) {
+					empty = false
+					break
+				}
+			}
+			return nil
+		})
+	} else {
+		err = boltDB.View(func(tx *bbolt.Tx) error {
+			k, _ := tx.Bucket(imdbBytes).Cursor().First()
+			empty = k == nil
+			return nil
+		})
+	}
+	return empty, err
+}
+
+func hasMetadataPrefix(key []byte) bool {
+	return len(key) >= len(metadataPrefix) && string(key[:len(metadataPrefix)]) == metadataPrefix
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+)
+
+// readVotes reads the "data.tsv" file of the "title.ratings.tsv.gz" archive and returns the number of votes per numeric part of the title IDs.
+// The numeric part is used as map key, because it requires much less memory than the ID as string for the over a million rated titles.
+func readVotes(path string) (map[uint32]uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	// The first row is just the headers: "tconst", "averageRating" and "numVotes"
+	if !s.Scan() || len(strings.Split(s.Text(), "\t")) != 3 {
+		return nil, fmt.Errorf("the TSV file doesn't seem to contain any ratings")
+	}
+	votes := make(map[uint32]uint32)
+	for i := 1; s.Scan(); i++ {
+		record := strings.Split(s.Text(), "\t")
+		if len(record) != 3 {
+			return nil, fmt.Errorf("row %v didn't have the expected number of columns: %#v", i, record)
+		}
+		kind, number, err := imdbid.Parse(record[0])
+		if err != nil || kind != imdbid.KindTitle {
+			return nil, fmt.Errorf("row %v has an invalid title ID: %v", i, record[0])
+		}
+		numVotes, err := strconv.ParseUint(record[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("row %v has an invalid number of votes: %v", i, record[2])
+		}
+		votes[number] = uint32(numVotes)
+	}
+	return votes, s.Err()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// jsonCache is an LRU cache for JSON responses, so that the HTTP handler doesn't have to unmarshal the protocol buffer and marshal it into JSON for popular IDs.
+// It's safe for concurrent use.
+type jsonCache struct {
+	maxEntries int
+	lock       sync.Mutex
+	ll         *list.List
+	entries    map[string]*list.Element
+}
+
+type jsonCacheEntry struct {
+	id   string
+	json []byte
+}
+
+func newJSONCache(maxEntries int) *jsonCache {
+	return &jsonCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element, maxEntries),
+	}
+}
+
+// Get returns the cached JSON for the given ID. The returned slice must not be modified.
+func (c *jsonCache) Get(id string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*jsonCacheEntry).json, true
+}
+
+// Add adds the JSON for the given ID to the cache, evicting the least recently used entry if the cache is full.
+// The slice must not be modified afterwards.
+func (c *jsonCache) Add(id string, json []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		c.ll.MoveToFront(elem)
+		elem.Value.(*jsonCacheEntry).json = json
+		return
+	}
+	c.entries[id] = c.ll.PushFront(&jsonCacheEntry{id: id, json: json})
+	if c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.entries, oldest.Value.(*jsonCacheEntry).id)
+	}
+}
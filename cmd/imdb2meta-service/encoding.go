@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	mimeApplicationProtobuf = "application/x-protobuf"
+	mimeApplicationCBOR     = "application/cbor"
+	mimeApplicationMsgpack  = "application/msgpack"
+)
+
+// cborEncMode sorts the map keys, so the same message is always encoded the same way, like with MessagePack.
+var cborEncMode, _ = cbor.CoreDetEncOptions().EncMode()
+
+// responseFormats are the MIME types of the formats that the HTTP endpoints can respond with. The first one is the default.
+var responseFormats = []string{fiber.MIMEApplicationJSON, mimeApplicationProtobuf, mimeApplicationCBOR, mimeApplicationMsgpack}
+
+// responseFormat returns the MIME type of the format that the client accepts according to the Accept header, out of the given formats of the endpoint.
+// The first format is the default, also when the client doesn't accept any of the formats.
+func responseFormat(c *fiber.Ctx, formats []string) string {
+	c.Vary(fiber.HeaderAccept)
+	format := c.Accepts(formats...)
+	if format == "" {
+		return formats[0]
+	}
+	return format
+}
+
+// sendMessage sends the message in the format that the client accepts.
+func sendMessage(c *fiber.Ctx, msg proto.Message) error {
+	return sendMessageAs(c, msg, responseFormat(c, responseFormats))
+}
+
+// sendMessageAs sends the message in the format with the given MIME type.
+func sendMessageAs(c *fiber.Ctx, msg proto.Message, format string) error {
+	body, err := marshalMessage(msg, format, marshalOptions(c))
+	if err != nil {
+		log.Printf("Couldn't marshal object into %v: %v\n", format, err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	setContentType(c, format)
+	return c.Send(body)
+}
+
+// marshalMessage marshals the message into the format with the given MIME type.
+// CBOR and MessagePack have the same structure as JSON, so the options apply to them as well, except for the indentation.
+func marshalMessage(msg proto.Message, format string, opts protojson.MarshalOptions) ([]byte, error) {
+	switch format {
+	case mimeApplicationProtobuf:
+		return proto.Marshal(msg)
+	case mimeApplicationCBOR:
+		return cborEncMode.Marshal(messageToMap(msg.ProtoReflect(), opts))
+	case mimeApplicationMsgpack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetSortMapKeys(true)
+		enc.UseCompactInts(true)
+		if err := enc.Encode(messageToMap(msg.ProtoReflect(), opts)); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return opts.Marshal(msg)
+	}
+}
+
+func setContentType(c *fiber.Ctx, format string) {
+	if format == fiber.MIMEApplicationJSON {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	} else {
+		c.Set(fiber.HeaderContentType, format)
+	}
+}
+
+// marshalOptionsKey is the key of the request's JSON marshal options in the locals of the fiber context.
+const marshalOptionsKey = "marshalOptions"
+
+// marshalOptionsParams are the query parameters of the marshal options middleware.
+var marshalOptionsParams = map[string]bool{"emitDefaults": true, "fieldNames": true, "enumsAsInts": true, "pretty": true}
+
+// createMarshalOptionsMiddleware creates a middleware that determines the JSON marshal options of a request from the query parameters
+// "emitDefaults", "fieldNames" ("camel" or "snake"), "enumsAsInts" and "pretty", with the given options as defaults.
+func createMarshalOptionsMiddleware(defaults protojson.MarshalOptions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		opts := defaults
+		for _, param := range []struct {
+			key   string
+			value *bool
+		}{
+			{"emitDefaults", &opts.EmitUnpopulated},
+			{"enumsAsInts", &opts.UseEnumNumbers},
+			{"pretty", &opts.Multiline},
+		} {
+			if value := c.Query(param.key); value != "" {
+				var err error
+				if *param.value, err = strconv.ParseBool(value); err != nil {
+					c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+					return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("The query parameter %q must be a boolean", param.key))
+				}
+			}
+		}
+		switch c.Query("fieldNames") {
+		case "":
+		case "camel":
+			opts.UseProtoNames = false
+		case "snake":
+			opts.UseProtoNames = true
+		default:
+			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+			return c.Status(fiber.StatusBadRequest).SendString(`The query parameter "fieldNames" must be "camel" or "snake"`)
+		}
+		opts.Indent = ""
+		if opts.Multiline {
+			opts.Indent = "    "
+		}
+
+		c.Locals(marshalOptionsKey, opts)
+		return c.Next()
+	}
+}
+
+// marshalOptions returns the JSON marshal options of the request. Without the marshal options middleware these are the protojson defaults.
+func marshalOptions(c *fiber.Ctx) protojson.MarshalOptions {
+	opts, _ := c.Locals(marshalOptionsKey).(protojson.MarshalOptions)
+	return opts
+}
+
+// messageToMap converts a message to a map with the same field names and values as its JSON representation with the given options, for formats without protocol buffer support.
+// Like in JSON, enum values are represented by their names, unless the options say otherwise.
+// Unlike in JSON, 64 bit integers are numbers, because the formats don't lose precision, and bytes stay bytes.
+func messageToMap(m protoreflect.Message, opts protojson.MarshalOptions) map[string]interface{} {
+	res := make(map[string]interface{})
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := fd.JSONName()
+		if opts.UseProtoNames {
+			name = string(fd.Name())
+		}
+		if !m.Has(fd) {
+			// Like protojson, unpopulated oneof fields (including proto3 optional fields) are never emitted
+			if !opts.EmitUnpopulated || fd.ContainingOneof() != nil {
+				continue
+			} else if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+				res[name] = nil
+				continue
+			}
+		}
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]interface{}, list.Len())
+			for i := range values {
+				values[i] = fieldValue(fd, list.Get(i), opts)
+			}
+			res[name] = values
+		case fd.IsMap():
+			values := make(map[string]interface{}, v.Map().Len())
+			v.Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				values[key.String()] = fieldValue(fd.MapValue(), value, opts)
+				return true
+			})
+			res[name] = values
+		default:
+			res[name] = fieldValue(fd, v, opts)
+		}
+	}
+	return res
+}
+
+func fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, opts protojson.MarshalOptions) interface{} {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if !opts.UseEnumNumbers {
+			if enumValue := fd.Enum().Values().ByNumber(v.Enum()); enumValue != nil {
+				return string(enumValue.Name())
+			}
+		}
+		return int32(v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageToMap(v.Message(), opts)
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// getFacets counts the titles that match the filter of the request.
+func getFacets(titleIndex *index.Index, req *pb.FacetsRequest) (*pb.FacetsResponse, error) {
+	filter, err := toIndexFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	facets := titleIndex.Facets(filter)
+	res := &pb.FacetsResponse{
+		Total:      int32(facets.Total),
+		TitleTypes: make([]*pb.TitleTypeCount, 0, len(facets.TitleTypes)),
+		Genres:     make([]*pb.GenreCount, 0, len(facets.Genres)),
+		Decades:    make([]*pb.DecadeCount, 0, len(facets.Decades)),
+		Adult:      int32(facets.Adult),
+		NonAdult:   int32(facets.NonAdult),
+	}
+	for _, count := range facets.TitleTypes {
+		res.TitleTypes = append(res.TitleTypes, &pb.TitleTypeCount{TitleType: count.TitleType, Count: int32(count.Count)})
+	}
+	for _, count := range facets.Genres {
+		res.Genres = append(res.Genres, &pb.GenreCount{Genre: count.Genre, Count: int32(count.Count)})
+	}
+	for _, count := range facets.Decades {
+		res.Decades = append(res.Decades, &pb.DecadeCount{Decade: int32(count.Decade), Count: int32(count.Count)})
+	}
+	return res, nil
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// GraphQL API, with types that are generated from the protocol buffer messages, so the schema always mirrors them.
+
+var errGraphQLInternal = errors.New("internal error")
+
+// newGraphQLSchema creates the GraphQL schema with the queries "title", "titles" and, if there's a title index, "search".
+func newGraphQLSchema(metaStore *metaStore, titleIndex *index.Index, maxBatchSize int) (graphql.Schema, error) {
+	types := &graphQLTypes{
+		objects: make(map[protoreflect.FullName]*graphql.Object),
+		enums:   make(map[protoreflect.FullName]*graphql.Enum),
+	}
+	metaType := types.object((&pb.Meta{}).ProtoReflect().Descriptor())
+
+	fields := graphql.Fields{
+		"title": &graphql.Field{
+			Type:        metaType,
+			Description: "Title with the IMDb ID, null if it doesn't exist",
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, _ := p.Args["id"].(string)
+				return graphQLMetaLoader(p.Context).load([]string{id}, false), nil
+			},
+		},
+		"titles": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(metaType)),
+			Description: "Titles with the IMDb IDs in the same order, with null for the ones that don't exist",
+			Args: graphql.FieldConfigArgument{
+				"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				idArgs, _ := p.Args["ids"].([]interface{})
+				if len(idArgs) > maxBatchSize {
+					return nil, fmt.Errorf("too many IDs, the maximum is %v", maxBatchSize)
+				}
+				ids := make([]string, len(idArgs))
+				for i, id := range idArgs {
+					ids[i], _ = id.(string)
+				}
+				return graphQLMetaLoader(p.Context).load(ids, true), nil
+			},
+		},
+	}
+	if titleIndex != nil {
+		fields["search"] = &graphql.Field{
+			Type:        graphql.NewNonNull(types.object((&pb.SearchResponse{}).ProtoReflect().Descriptor())),
+			Description: "Titles that match the query, like the HTTP endpoint /search",
+			Args: graphql.FieldConfigArgument{
+				"q":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				"fuzzy":  &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				query, _ := p.Args["q"].(string)
+				offset, _ := p.Args["offset"].(int)
+				limit, _ := p.Args["limit"].(int)
+				fuzzy, _ := p.Args["fuzzy"].(bool)
+				res, err := search(metaStore, titleIndex, &pb.SearchRequest{
+					Query:  query,
+					Offset: int32(offset),
+					Limit:  int32(limit),
+					Fuzzy:  fuzzy,
+				})
+				if err != nil {
+					if err == errInvalidPagination {
+						return nil, err
+					}
+					log.Printf("Couldn't search titles: %v\n", err)
+					return nil, errGraphQLInternal
+				}
+				return res, nil
+			},
+		}
+	}
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: fields,
+		}),
+	})
+}
+
+// graphQLTypes creates the GraphQL types for protocol buffer messages and enums, each only once.
+type graphQLTypes struct {
+	objects map[protoreflect.FullName]*graphql.Object
+	enums   map[protoreflect.FullName]*graphql.Enum
+}
+
+// object returns the object type for the message. Its fields have the names of the JSON representation.
+// Fields with presence, like nested messages, are nullable, all others aren't. Map fields aren't supported and left out.
+func (t *graphQLTypes) object(md protoreflect.MessageDescriptor) *graphql.Object {
+	if object, ok := t.objects[md.FullName()]; ok {
+		return object
+	}
+	object := graphql.NewObject(graphql.ObjectConfig{
+		Name: string(md.Name()),
+		// A thunk, so that messages can reference each other
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := graphql.Fields{}
+			mdFields := md.Fields()
+			for i := 0; i < mdFields.Len(); i++ {
+				fd := mdFields.Get(i)
+				if fd.IsMap() {
+					continue
+				}
+				fieldType := t.scalarType(fd)
+				if fd.IsList() {
+					fieldType = graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(fieldType)))
+				} else if !fd.HasPresence() {
+					fieldType = graphql.NewNonNull(fieldType)
+				}
+				fields[fd.JSONName()] = &graphql.Field{
+					Type:    fieldType,
+					Resolve: resolveProtoField(fd),
+				}
+			}
+			return fields
+		}),
+	})
+	t.objects[md.FullName()] = object
+	return object
+}
+
+// scalarType returns the type of a single value of the field. 64 bit integers and bytes are strings, like in JSON.
+func (t *graphQLTypes) scalarType(fd protoreflect.FieldDescriptor) graphql.Output {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return graphql.Boolean
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind, protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return graphql.Int
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return graphql.Float
+	case protoreflect.EnumKind:
+		return t.enum(fd.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return t.object(fd.Message())
+	default:
+		return graphql.String
+	}
+}
+
+func (t *graphQLTypes) enum(ed protoreflect.EnumDescriptor) *graphql.Enum {
+	if enum, ok := t.enums[ed.FullName()]; ok {
+		return enum
+	}
+	values := graphql.EnumValueConfigMap{}
+	edValues := ed.Values()
+	for i := 0; i < edValues.Len(); i++ {
+		values[string(edValues.Get(i).Name())] = &graphql.EnumValueConfig{Value: edValues.Get(i).Number()}
+	}
+	enum := graphql.NewEnum(graphql.EnumConfig{
+		Name:   string(ed.Name()),
+		Values: values,
+	})
+	t.enums[ed.FullName()] = enum
+	return enum
+}
+
+// resolveProtoField creates a resolver for the field of a protocol buffer message as source.
+func resolveProtoField(fd protoreflect.FieldDescriptor) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		msg, ok := p.Source.(proto.Message)
+		if !ok {
+			return nil, nil
+		}
+		m := msg.ProtoReflect()
+		if fd.HasPresence() && !m.Has(fd) {
+			return nil, nil
+		}
+		v := m.Get(fd)
+		if fd.IsList() {
+			list := v.List()
+			values := make([]interface{}, list.Len())
+			for i := range values {
+				values[i] = graphQLValue(fd, list.Get(i))
+			}
+			return values, nil
+		}
+		return graphQLValue(fd, v), nil
+	}
+}
+
+func graphQLValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		return v.Enum()
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return v.Message().Interface()
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	default:
+		return v.Interface()
+	}
+}
+
+type metaLoaderKey struct{}
+
+// metaLoader loads the metas for the resolvers of a GraphQL request.
+// Instead of reading the DB for each title, the resolvers only register the IDs and return thunks.
+// graphql-go calls the thunks after all fields of the same level in the query are resolved, and the first thunk reads all registered IDs at once.
+// graphql-go resolves the fields sequentially, so the loader doesn't need to be safe for concurrent use.
+type metaLoader struct {
+	metaStore *metaStore
+	// Maximum number of IDs of all fields of a query together, because aliases allow any number of fields
+	maxIDs  int
+	pending []string
+	// With nil for IDs that don't exist
+	metas map[string]*pb.Meta
+}
+
+func newMetaLoader(metaStore *metaStore, maxIDs int) *metaLoader {
+	return &metaLoader{
+		metaStore: metaStore,
+		maxIDs:    maxIDs,
+		metas:     make(map[string]*pb.Meta),
+	}
+}
+
+func graphQLMetaLoader(ctx context.Context) *metaLoader {
+	return ctx.Value(metaLoaderKey{}).(*metaLoader)
+}
+
+// load registers the IDs and returns a thunk that returns their metas, as list or, if list is false, the meta of the only ID.
+// The thunk returns an error if the query contains more than maxIDs IDs, without reading them.
+func (l *metaLoader) load(ids []string, list bool) func() (interface{}, error) {
+	var newIDs []string
+	for _, id := range ids {
+		if _, ok := l.metas[id]; !ok {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(l.metas)+len(l.pending)+len(newIDs) > l.maxIDs {
+		return func() (interface{}, error) {
+			return nil, fmt.Errorf("too many IDs in the query, the maximum is %v", l.maxIDs)
+		}
+	}
+	l.pending = append(l.pending, newIDs...)
+	return func() (interface{}, error) {
+		if err := l.fetchPending(); err != nil {
+			log.Printf("Couldn't get metas: %v\n", err)
+			return nil, errGraphQLInternal
+		}
+		// Missing metas must be untyped nil, otherwise graphql-go resolves their fields
+		if !list {
+			if meta := l.metas[ids[0]]; meta != nil {
+				return meta, nil
+			}
+			return nil, nil
+		}
+		metas := make([]interface{}, len(ids))
+		for i, id := range ids {
+			if meta := l.metas[id]; meta != nil {
+				metas[i] = meta
+			}
+		}
+		return metas, nil
+	}
+}
+
+func (l *metaLoader) fetchPending() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+	metas, err := getMetaList(l.metaStore, l.pending)
+	if err != nil {
+		return err
+	}
+	for i, id := range l.pending {
+		l.metas[id] = metas[i]
+	}
+	l.pending = nil
+	return nil
+}
+
+// createGraphQLHandler creates a handler that executes GraphQL queries.
+// The query, variables and operation name are read from the JSON body of POST requests or the query parameters of GET requests.
+func createGraphQLHandler(schema graphql.Schema, metaStore *metaStore, maxBatchSize int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req struct {
+			Query         string                 `json:"query"`
+			Variables     map[string]interface{} `json:"variables"`
+			OperationName string                 `json:"operationName"`
+		}
+		if c.Method() == fiber.MethodPost {
+			if err := json.Unmarshal(c.Body(), &req); err != nil {
+				c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+				return c.Status(fiber.StatusBadRequest).SendString("The request body must be a JSON object with the GraphQL query")
+			}
+		} else {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			if variables := c.Query("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+					return c.Status(fiber.StatusBadRequest).SendString(`The query parameter "variables" must be a JSON object`)
+				}
+			}
+		}
+		if req.Query == "" {
+			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+			return c.Status(fiber.StatusBadRequest).SendString("The GraphQL query is required")
+		}
+
+		res := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        context.WithValue(c.Context(), metaLoaderKey{}, newMetaLoader(metaStore, maxBatchSize)),
+		})
+		return c.JSON(res)
+	}
+}
@@ -2,24 +2,31 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/deflix-tv/imdb2meta/index"
 	"github.com/deflix-tv/imdb2meta/pb"
 )
 
 // grpcServer is used to implement imdb2meta.MetaFetcher.
 type grpcServer struct {
 	pb.UnimplementedMetaFetcherServer
-	metaStore *metaStore
+	metaStore    *metaStore
+	maxBatchSize int
+	titleIndex   *index.Index // Can be nil
 }
 
-func createGRPCserver(metaStore *metaStore) *grpcServer {
+func createGRPCserver(metaStore *metaStore, maxBatchSize int, titleIndex *index.Index) *grpcServer {
 	return &grpcServer{
-		metaStore: metaStore,
+		metaStore:    metaStore,
+		maxBatchSize: maxBatchSize,
+		titleIndex:   titleIndex,
 	}
 }
 
@@ -46,3 +53,229 @@ func (s *grpcServer) Get(ctx context.Context, in *pb.MetaRequest) (*pb.Meta, err
 
 	return meta, nil
 }
+
+// GetMany implements imdb2meta.MetaFetcher.
+func (s *grpcServer) GetMany(ctx context.Context, in *pb.MetasRequest) (*pb.MetasResponse, error) {
+	if len(in.Ids) > s.maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Too many IDs, the maximum is %v", s.maxBatchSize)
+	}
+
+	res, err := getMetas(s.metaStore, in.Ids)
+	if err != nil {
+		log.Printf("Couldn't get metas: %v\n", err)
+		// Note: Don't expose internal error details like DB file locations to clients
+		return nil, status.Error(codes.Internal, "Couldn't get metas")
+	}
+
+	return res, nil
+}
+
+// streamBatchSize is the number of IDs that are read from the DB in one transaction when streaming metas for a list of IDs.
+const streamBatchSize = 100
+
+// errStreamCanceled is used to stop iterating over the DB when the client canceled the stream.
+var errStreamCanceled = errors.New("stream canceled")
+
+// StreamMetas implements imdb2meta.MetaFetcher.
+// Flow control is handled by gRPC: Send blocks when the client doesn't keep up with receiving.
+func (s *grpcServer) StreamMetas(in *pb.StreamMetasRequest, stream pb.MetaFetcher_StreamMetasServer) error {
+	switch query := in.Query.(type) {
+	case *pb.StreamMetasRequest_Ids:
+		ids := query.Ids.GetIds()
+		for len(ids) > 0 {
+			batchSize := streamBatchSize
+			if len(ids) < batchSize {
+				batchSize = len(ids)
+			}
+			res, err := getMetas(s.metaStore, ids[:batchSize])
+			if err != nil {
+				log.Printf("Couldn't get metas: %v\n", err)
+				return status.Error(codes.Internal, "Couldn't get metas")
+			}
+			for _, meta := range res.Metas {
+				if err = stream.Send(meta); err != nil {
+					return err
+				}
+			}
+			ids = ids[batchSize:]
+		}
+		return nil
+	case *pb.StreamMetasRequest_Filter:
+		ctx := stream.Context()
+		var sendErr error
+		err := s.metaStore.ForEach(func(metaBytes []byte) error {
+			if ctx.Err() != nil {
+				return errStreamCanceled
+			}
+			meta := &pb.Meta{}
+			if err := proto.Unmarshal(metaBytes, meta); err != nil {
+				return err
+			}
+			if !matchesFilter(meta, query.Filter) {
+				return nil
+			}
+			if sendErr = stream.Send(meta); sendErr != nil {
+				return errStreamCanceled
+			}
+			return nil
+		})
+		if err == errStreamCanceled {
+			if sendErr != nil {
+				return sendErr
+			}
+			return status.FromContextError(ctx.Err()).Err()
+		} else if err != nil {
+			log.Printf("Couldn't iterate over metas: %v\n", err)
+			return status.Error(codes.Internal, "Couldn't iterate over metas")
+		}
+		return nil
+	default:
+		return status.Error(codes.InvalidArgument, "Either a list of IDs or a filter is required")
+	}
+}
+
+// Search implements imdb2meta.MetaFetcher.
+func (s *grpcServer) Search(ctx context.Context, in *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if s.titleIndex == nil {
+		return nil, status.Error(codes.Unimplemented, "The service was started without a title index")
+	}
+	if in.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "A query is required")
+	}
+
+	res, err := search(s.metaStore, s.titleIndex, in)
+	if err != nil {
+		if err == errInvalidPagination {
+			return nil, status.Error(codes.InvalidArgument, "Offset and limit must not be negative")
+		} else if err == errInvalidTitleType {
+			return nil, status.Error(codes.InvalidArgument, "Unknown title type")
+		}
+		log.Printf("Couldn't search titles: %v\n", err)
+		return nil, status.Error(codes.Internal, "Couldn't search titles")
+	}
+
+	return res, nil
+}
+
+// Resolve implements imdb2meta.MetaFetcher.
+func (s *grpcServer) Resolve(ctx context.Context, in *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	if s.titleIndex == nil {
+		return nil, status.Error(codes.Unimplemented, "The service was started without a title index")
+	}
+	if in.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "A name is required")
+	}
+
+	res, err := resolve(s.metaStore, s.titleIndex, in.Name, int(in.Limit))
+	if err != nil {
+		if err == errInvalidPagination {
+			return nil, status.Error(codes.InvalidArgument, "Limit must not be negative")
+		}
+		log.Printf("Couldn't resolve release name: %v\n", err)
+		return nil, status.Error(codes.Internal, "Couldn't resolve release name")
+	}
+
+	return res, nil
+}
+
+// Suggest implements imdb2meta.MetaFetcher.
+func (s *grpcServer) Suggest(ctx context.Context, in *pb.SuggestRequest) (*pb.SuggestResponse, error) {
+	if s.titleIndex == nil {
+		return nil, status.Error(codes.Unimplemented, "The service was started without a title index")
+	}
+	if in.Prefix == "" {
+		return nil, status.Error(codes.InvalidArgument, "A prefix is required")
+	}
+
+	res, err := suggest(s.metaStore, s.titleIndex, in.Prefix, int(in.Limit))
+	if err != nil {
+		if err == errInvalidPagination {
+			return nil, status.Error(codes.InvalidArgument, "Limit must not be negative")
+		}
+		log.Printf("Couldn't suggest titles: %v\n", err)
+		return nil, status.Error(codes.Internal, "Couldn't suggest titles")
+	}
+
+	return res, nil
+}
+
+// ListTitles implements imdb2meta.MetaFetcher.
+func (s *grpcServer) ListTitles(ctx context.Context, in *pb.ListTitlesRequest) (*pb.ListTitlesResponse, error) {
+	if s.titleIndex == nil {
+		return nil, status.Error(codes.Unimplemented, "The service was started without a title index")
+	}
+
+	res, err := listTitles(s.metaStore, s.titleIndex, in)
+	if err != nil {
+		if err == errInvalidPagination {
+			return nil, status.Error(codes.InvalidArgument, "Limit must not be negative")
+		} else if err == errInvalidCursor {
+			return nil, status.Error(codes.InvalidArgument, "Invalid cursor")
+		} else if err == errInvalidTitleType {
+			return nil, status.Error(codes.InvalidArgument, "Unknown title type")
+		}
+		log.Printf("Couldn't list titles: %v\n", err)
+		return nil, status.Error(codes.Internal, "Couldn't list titles")
+	}
+
+	return res, nil
+}
+
+// GetFacets implements imdb2meta.MetaFetcher.
+func (s *grpcServer) GetFacets(ctx context.Context, in *pb.FacetsRequest) (*pb.FacetsResponse, error) {
+	if s.titleIndex == nil {
+		return nil, status.Error(codes.Unimplemented, "The service was started without a title index")
+	}
+
+	res, err := getFacets(s.titleIndex, in)
+	if err != nil {
+		if err == errInvalidTitleType {
+			return nil, status.Error(codes.InvalidArgument, "Unknown title type")
+		}
+		log.Printf("Couldn't count facets: %v\n", err)
+		return nil, status.Error(codes.Internal, "Couldn't count facets")
+	}
+
+	return res, nil
+}
+
+// matchesFilter checks if the meta matches all conditions of the filter.
+func matchesFilter(meta *pb.Meta, filter *pb.MetaFilter) bool {
+	if len(filter.TitleTypes) > 0 {
+		found := false
+		for _, titleType := range filter.TitleTypes {
+			if meta.TitleType == titleType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.StartYearFrom != 0 && meta.StartYear < filter.StartYearFrom {
+		return false
+	}
+	if filter.StartYearTo != 0 && (meta.StartYear == 0 || meta.StartYear > filter.StartYearTo) {
+		return false
+	}
+	if filter.Genre != "" {
+		found := false
+		for _, genre := range meta.Genres {
+			if strings.EqualFold(genre, filter.Genre) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.MinRuntime != 0 && meta.Runtime < filter.MinRuntime {
+		return false
+	}
+	if filter.Adult != nil && meta.IsAdult != *filter.Adult {
+		return false
+	}
+	return true
+}
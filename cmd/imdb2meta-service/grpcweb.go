@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// gRPC-Web and Connect protocol support, for browser clients that can't use gRPC.
+//
+// Both protocols use POST requests to "/<service>/<method>", like gRPC, and are distinguished by the content type:
+// - gRPC-Web (https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md): "application/grpc-web(+proto|+json)" and "application/grpc-web-text(+proto)".
+//   The messages are enveloped like with gRPC, and the status is sent as last envelope, because browsers can't read HTTP trailers.
+// - Connect (https://connectrpc.com/docs/protocol): "application/(proto|json)" for unary RPCs, with the plain message as body,
+//   and "application/connect+(proto|json)" for streaming RPCs, with enveloped messages and a JSON end-of-stream envelope.
+// Compressed messages aren't supported.
+
+const (
+	envelopeFlagCompressed = 0x01
+	// Connect end-of-stream envelope
+	envelopeFlagEndStream = 0x02
+	// gRPC-Web trailers envelope
+	envelopeFlagTrailers = 0x80
+)
+
+// webProtocol is the protocol of a gRPC-Web or Connect request, determined by its content type.
+type webProtocol struct {
+	connect bool
+	// Connect unary RPCs use plain messages, everything else uses envelopes
+	enveloped bool
+	// gRPC-Web text encodes the request and response body with base64
+	base64 bool
+	json   bool
+	// The content type of the response
+	contentType string
+}
+
+func parseWebProtocol(contentType string) (webProtocol, bool) {
+	// Parameters like "charset=utf-8" are irrelevant
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	switch contentType {
+	case "application/grpc-web", "application/grpc-web+proto":
+		return webProtocol{enveloped: true, contentType: "application/grpc-web+proto"}, true
+	case "application/grpc-web+json":
+		return webProtocol{enveloped: true, json: true, contentType: contentType}, true
+	case "application/grpc-web-text", "application/grpc-web-text+proto":
+		return webProtocol{enveloped: true, base64: true, contentType: "application/grpc-web-text+proto"}, true
+	case "application/proto":
+		return webProtocol{connect: true, contentType: contentType}, true
+	case "application/json":
+		return webProtocol{connect: true, json: true, contentType: contentType}, true
+	case "application/connect+proto":
+		return webProtocol{connect: true, enveloped: true, contentType: contentType}, true
+	case "application/connect+json":
+		return webProtocol{connect: true, enveloped: true, json: true, contentType: contentType}, true
+	default:
+		return webProtocol{}, false
+	}
+}
+
+func (p webProtocol) marshal(m proto.Message) ([]byte, error) {
+	if p.json {
+		return protojson.Marshal(m)
+	}
+	return proto.Marshal(m)
+}
+
+func (p webProtocol) unmarshal(b []byte, m proto.Message) error {
+	if p.json {
+		// Clients with a newer version of the .proto file can send fields that the service doesn't know yet
+		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+	}
+	return proto.Unmarshal(b, m)
+}
+
+// registerWebRoutes registers the routes for gRPC-Web and Connect requests for the RPCs of the service,
+// with the handlers being called before the RPC handler, for example middlewares.
+func registerWebRoutes(router fiber.Router, serviceDesc *grpc.ServiceDesc, srv interface{}, handlers ...fiber.Handler) error {
+	rpcs, err := serviceRPCs(serviceDesc, srv)
+	if err != nil {
+		return err
+	}
+	for _, r := range rpcs {
+		path := "/" + serviceDesc.ServiceName + "/" + string(r.desc.Name())
+		router.Post(path, append(handlers, createWebHandler(r))...)
+	}
+	return nil
+}
+
+func createWebHandler(r *rpc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		protocol, ok := parseWebProtocol(c.Get(fiber.HeaderContentType))
+		// Connect unary requests can only be used for unary RPCs, otherwise the client must use the streaming content type
+		if !ok || !protocol.enveloped && r.streamHandler != nil {
+			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+			return c.Status(fiber.StatusUnsupportedMediaType).SendString("Unsupported content type")
+		}
+
+		timeout, err := webRequestTimeout(c, protocol)
+		if err != nil {
+			return sendWebError(c, protocol, err)
+		}
+		req, err := readWebRequest(c, protocol, r)
+		if err != nil {
+			return sendWebError(c, protocol, err)
+		}
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), timeout)
+		} else {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+
+		if !protocol.enveloped {
+			defer cancel()
+			res, err := r.callUnary(ctx, req)
+			if err != nil {
+				return sendWebError(c, protocol, err)
+			}
+			body, err := protocol.marshal(res)
+			if err != nil {
+				return sendWebError(c, protocol, status.Errorf(codes.Internal, "Couldn't marshal response: %v", err))
+			}
+			c.Set(fiber.HeaderContentType, protocol.contentType)
+			return c.Send(body)
+		}
+
+		// Enveloped responses are streamed, with the status at the end, so the HTTP status is always 200
+		c.Set(fiber.HeaderContentType, protocol.contentType)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+			var err, writeErr error
+			send := func(m proto.Message) error {
+				msg, err := protocol.marshal(m)
+				if err != nil {
+					return status.Errorf(codes.Internal, "Couldn't marshal response: %v", err)
+				}
+				if writeErr = writeEnvelope(w, protocol, 0, msg); writeErr != nil {
+					return status.Error(codes.Canceled, "Couldn't write response")
+				}
+				return nil
+			}
+			if r.streamHandler != nil {
+				err = r.callStream(ctx, req, send)
+			} else {
+				var res proto.Message
+				if res, err = r.callUnary(ctx, req); err == nil {
+					err = send(res)
+				}
+			}
+			if writeErr == nil {
+				flag, msg := webStatusEnvelope(protocol, err)
+				_ = writeEnvelope(w, protocol, flag, msg)
+			}
+		})
+		return nil
+	}
+}
+
+// webRequestTimeout returns the timeout of an RPC from the "grpc-timeout" header for gRPC-Web or "Connect-Timeout-Ms" header for Connect. 0 means no timeout.
+func webRequestTimeout(c *fiber.Ctx, protocol webProtocol) (time.Duration, error) {
+	if protocol.connect {
+		value := c.Get("Connect-Timeout-Ms")
+		if value == "" {
+			return 0, nil
+		}
+		ms, err := strconv.ParseUint(value, 10, 63)
+		if err != nil || len(value) > 10 {
+			return 0, status.Error(codes.InvalidArgument, "Invalid Connect-Timeout-Ms header")
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	value := c.Get("Grpc-Timeout")
+	if value == "" {
+		return 0, nil
+	}
+	units := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond}
+	n, err := strconv.ParseUint(value[:len(value)-1], 10, 32)
+	unit, ok := units[value[len(value)-1]]
+	if err != nil || !ok || len(value) > 9 {
+		return 0, status.Error(codes.InvalidArgument, "Invalid grpc-timeout header")
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// readWebRequest unmarshals the request message from the request body.
+func readWebRequest(c *fiber.Ctx, protocol webProtocol, r *rpc) (proto.Message, error) {
+	if protocol.connect && !protocol.enveloped {
+		if encoding := c.Get(fiber.HeaderContentEncoding); encoding != "" && encoding != "identity" {
+			return nil, status.Errorf(codes.Unimplemented, "Unsupported content encoding %q", encoding)
+		}
+	}
+	body := c.Body()
+	if protocol.base64 {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(body)))
+		n, err := base64.StdEncoding.Decode(decoded, body)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid base64 request body: %v", err)
+		}
+		body = decoded[:n]
+	}
+	if protocol.enveloped {
+		if len(body) < 5 {
+			return nil, status.Error(codes.InvalidArgument, "Missing request message")
+		}
+		flags, length := body[0], binary.BigEndian.Uint32(body[1:5])
+		if flags&envelopeFlagCompressed != 0 {
+			return nil, status.Error(codes.Unimplemented, "Compressed messages aren't supported")
+		} else if uint64(len(body)-5) != uint64(length) {
+			return nil, status.Error(codes.InvalidArgument, "Invalid request message length")
+		}
+		body = body[5:]
+	}
+	req := r.reqType.New().Interface()
+	if err := protocol.unmarshal(body, req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request message: %v", err)
+	}
+	return req, nil
+}
+
+// writeEnvelope writes a message with the envelope prefix of flags and length, and flushes it, so that clients receive streamed messages immediately.
+func writeEnvelope(w *bufio.Writer, protocol webProtocol, flags byte, msg []byte) error {
+	envelope := newEnvelope(flags, msg)
+	if protocol.base64 {
+		// Each envelope is encoded on its own, which clients support, because the padding keeps the chunks aligned
+		envelope = []byte(base64.StdEncoding.EncodeToString(envelope))
+	}
+	if _, err := w.Write(envelope); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func newEnvelope(flags byte, msg []byte) []byte {
+	envelope := make([]byte, 5, 5+len(msg))
+	envelope[0] = flags
+	binary.BigEndian.PutUint32(envelope[1:], uint32(len(msg)))
+	return append(envelope, msg...)
+}
+
+// webStatusEnvelope returns the flag and message of the last envelope of a response, which contains the status of the RPC.
+func webStatusEnvelope(protocol webProtocol, err error) (byte, []byte) {
+	if protocol.connect {
+		endStream := struct {
+			Error *connectError `json:"error,omitempty"`
+		}{}
+		if err != nil {
+			endStream.Error = newConnectError(status.Convert(err))
+		}
+		msg, _ := json.Marshal(endStream)
+		return envelopeFlagEndStream, msg
+	}
+	s := status.Convert(err)
+	return envelopeFlagTrailers, []byte(fmt.Sprintf("grpc-status: %d\r\ngrpc-message: %s\r\n", s.Code(), encodeGRPCMessage(s.Message())))
+}
+
+// sendWebError sends an error that occurs before the RPC sends any messages.
+// For gRPC-Web it's sent in the headers ("trailers-only" response), for Connect unary RPCs as JSON with the HTTP status code that corresponds to the status code.
+func sendWebError(c *fiber.Ctx, protocol webProtocol, err error) error {
+	s := status.Convert(err)
+	if !protocol.connect {
+		c.Set(fiber.HeaderContentType, protocol.contentType)
+		c.Set("Grpc-Status", strconv.Itoa(int(s.Code())))
+		c.Set("Grpc-Message", encodeGRPCMessage(s.Message()))
+		return c.Status(fiber.StatusOK).Send(nil)
+	} else if protocol.enveloped {
+		c.Set(fiber.HeaderContentType, protocol.contentType)
+		return c.Send(newEnvelope(webStatusEnvelope(protocol, err)))
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(httpStatusFromCode(s.Code())).JSON(newConnectError(s))
+}
+
+// connectError is the JSON representation of an error in the Connect protocol.
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func newConnectError(s *status.Status) *connectError {
+	// The Connect codes are the same as the gRPC codes, but in snake case
+	var code strings.Builder
+	for i, r := range s.Code().String() {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			code.WriteByte('_')
+		}
+		code.WriteRune(r)
+	}
+	return &connectError{
+		Code:    strings.ToLower(code.String()),
+		Message: s.Message(),
+	}
+}
+
+// encodeGRPCMessage percent-encodes the status message for the grpc-message header, like gRPC does.
+func encodeGRPCMessage(msg string) string {
+	var sb strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if b := msg[i]; b < ' ' || b > '~' || b == '%' {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		} else {
+			sb.WriteByte(b)
+		}
+	}
+	return sb.String()
+}
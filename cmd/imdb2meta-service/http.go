@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 
@@ -14,12 +15,38 @@ var healthHandler fiber.Handler = func(c *fiber.Ctx) error {
 	return c.SendString("OK")
 }
 
-func createMetaHandler(metaStore *metaStore) fiber.Handler {
+// createReadyHandler creates a handler that responds with 200 while the service is ready to serve requests and with 503 before and while it's shutting down.
+// Unlike "/health", which only reports that the process is alive, this is meant for load balancers and readiness probes.
+func createReadyHandler(r *readiness) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !r.isReady() {
+			return c.Status(fiber.StatusServiceUnavailable).SendString("Not ready")
+		}
+		return c.SendString("OK")
+	}
+}
+
+// createMetaHandler creates a handler that responds with the Meta for the requested ID, in the format that the client accepts.
+// Besides the formats of all endpoints, the Meta is also available as JSON-LD.
+// jsonCache can be nil, in which case every JSON request leads to unmarshalling the protocol buffer and marshalling it into JSON.
+// The cache only contains JSON that's marshalled with the server's default marshal options, so it's not used for requests with other options.
+func createMetaHandler(metaStore *metaStore, jsonCache *jsonCache, defaultMarshalOptions protojson.MarshalOptions) fiber.Handler {
+	formats := append(responseFormats[:len(responseFormats):len(responseFormats)], mimeApplicationLDJSON)
 	return func(c *fiber.Ctx) error {
 		id := c.Params("id")
 		if id == "" {
 			return c.SendStatus(fiber.StatusBadRequest)
 		}
+		format := responseFormat(c, formats)
+		opts := marshalOptions(c)
+		cacheable := jsonCache != nil && format == fiber.MIMEApplicationJSON && opts == defaultMarshalOptions
+
+		if cacheable {
+			if metaJSON, ok := jsonCache.Get(id); ok {
+				c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+				return c.Send(metaJSON)
+			}
+		}
 
 		metaBytes, err := metaStore.Get(id)
 		if err != nil {
@@ -30,6 +57,11 @@ func createMetaHandler(metaStore *metaStore) fiber.Handler {
 			log.Printf("Couldn't get data from DB: %v\n", err)
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
+		// The DB already contains the protocol buffer
+		if format == mimeApplicationProtobuf {
+			c.Set(fiber.HeaderContentType, mimeApplicationProtobuf)
+			return c.Send(metaBytes)
+		}
 
 		meta := &pb.Meta{}
 		err = proto.Unmarshal(metaBytes, meta)
@@ -37,13 +69,66 @@ func createMetaHandler(metaStore *metaStore) fiber.Handler {
 			log.Printf("Couldn't unmarshal protocol buffer into object: %v\n", err)
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
-		metaJSON, err := protojson.Marshal(meta)
+		if format == mimeApplicationLDJSON {
+			metaJSONLD, err := marshalJSONLD(meta)
+			if err != nil {
+				log.Printf("Couldn't marshal object into JSON-LD: %v\n", err)
+				return c.SendStatus(fiber.StatusInternalServerError)
+			}
+			c.Set(fiber.HeaderContentType, mimeApplicationLDJSON+"; charset=utf-8")
+			return c.Send(metaJSONLD)
+		} else if format != fiber.MIMEApplicationJSON {
+			return sendMessageAs(c, meta, format)
+		}
+		metaJSON, err := opts.Marshal(meta)
 		if err != nil {
 			log.Printf("Couldn't marshal object into JSON: %v\n", err)
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
+		if cacheable {
+			// The ID from the params is only valid during the request
+			jsonCache.Add(utils.ImmutableString(id), metaJSON)
+		}
 
 		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
 		return c.Send(metaJSON)
 	}
 }
+
+// legacyFilterParams are the query parameters of the legacy routes for the MetaFilter.
+var legacyFilterParams = map[string]string{
+	// Comma-separated title types like "movie" or "tvSeries"
+	"type":       "filter.title_types",
+	"yearFrom":   "filter.start_year_from",
+	"yearTo":     "filter.start_year_to",
+	"genre":      "filter.genre",
+	"minRuntime": "filter.min_runtime",
+	"adult":      "filter.adult",
+}
+
+// legacyRoutes are the routes from before the REST API. They're aliases of the RPCs' REST routes with their own query parameters.
+// The search routes respond with Unimplemented without title index, like the REST routes.
+var legacyRoutes = []legacyRoute{
+	// The body is a JSON array of IDs
+	{method: fiber.MethodPost, path: "/meta", rpc: "GetMany", body: "ids"},
+	{method: fiber.MethodGet, path: "/search", rpc: "Search", params: withLegacyFilterParams(map[string]string{
+		"q":      "query",
+		"offset": "offset",
+		"limit":  "limit",
+		"fuzzy":  "fuzzy",
+	})},
+	{method: fiber.MethodGet, path: "/resolve", rpc: "Resolve", params: map[string]string{"name": "name", "limit": "limit"}},
+	{method: fiber.MethodGet, path: "/suggest", rpc: "Suggest", params: map[string]string{"prefix": "prefix", "limit": "limit"}},
+	{method: fiber.MethodGet, path: "/titles", rpc: "ListTitles", params: withLegacyFilterParams(map[string]string{
+		"cursor": "cursor",
+		"limit":  "limit",
+	})},
+	{method: fiber.MethodGet, path: "/facets", rpc: "GetFacets", params: withLegacyFilterParams(map[string]string{})},
+}
+
+func withLegacyFilterParams(params map[string]string) map[string]string {
+	for name, path := range legacyFilterParams {
+		params[name] = path
+	}
+	return params
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// Compare with:
+//
+//	go test -run=^$ -bench=MetaHandler -benchmem ./cmd/imdb2meta-service
+func BenchmarkMetaHandler(b *testing.B) {
+	b.Run("transcode", func(b *testing.B) {
+		benchmarkMetaHandler(b, nil)
+	})
+	b.Run("jsonCache", func(b *testing.B) {
+		benchmarkMetaHandler(b, newJSONCache(1))
+	})
+}
+
+func benchmarkMetaHandler(b *testing.B, jsonCache *jsonCache) {
+	// An in-memory store with a single entry, so the benchmark isn't dominated by DB I/O
+	meta := &pb.Meta{
+		Id:           "tt1254207",
+		TitleType:    pb.TitleType_SHORT,
+		PrimaryTitle: "Big Buck Bunny",
+		StartYear:    2008,
+		Runtime:      10,
+		Genres:       []string{"Animation", "Comedy", "Short"},
+	}
+	metaBytes, err := proto.Marshal(meta)
+	if err != nil {
+		b.Fatal(err)
+	}
+	memStore := &memStore{
+		keys:       []byte(meta.Id),
+		keyOffsets: []uint32{0, uint32(len(meta.Id))},
+		values:     metaBytes,
+		valOffsets: []uint32{0, uint32(len(metaBytes))},
+	}
+	metaStore := &metaStore{
+		memStore:    memStore,
+		keyEncoding: imdbid.KeyEncodingString,
+	}
+
+	app := fiber.New()
+	app.Get("/meta/:id", createMetaHandler(metaStore, jsonCache, protojson.MarshalOptions{}))
+	handler := app.Handler()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/meta/tt1254207")
+	ctx.Request.Header.SetMethod(fiber.MethodGet)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.Response.Reset()
+		handler(ctx)
+		if ctx.Response.StatusCode() != fiber.StatusOK {
+			b.Fatalf("Unexpected status code: %v", ctx.Response.StatusCode())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// JSON-LD with the schema.org vocabulary, see https://schema.org/Movie
+
+const mimeApplicationLDJSON = "application/ld+json"
+
+// schemaOrgTypes maps the title types to schema.org types. All other title types are movies.
+var schemaOrgTypes = map[pb.TitleType]string{
+	pb.TitleType_TV_SERIES:      "TVSeries",
+	pb.TitleType_TV_MINI_SERIES: "TVSeries",
+	pb.TitleType_TV_EPISODE:     "TVEpisode",
+	pb.TitleType_EPISODE:        "TVEpisode",
+	pb.TitleType_VIDEO_GAME:     "VideoGame",
+	pb.TitleType_AUDIOBOOK:      "Audiobook",
+	pb.TitleType_RADIO_SERIES:   "RadioSeries",
+}
+
+type jsonLD struct {
+	Context       string   `json:"@context"`
+	Type          string   `json:"@type"`
+	ID            string   `json:"@id"`
+	Name          string   `json:"name"`
+	AlternateName string   `json:"alternateName,omitempty"`
+	SameAs        string   `json:"sameAs"`
+	DatePublished string   `json:"datePublished,omitempty"`
+	StartDate     string   `json:"startDate,omitempty"`
+	EndDate       string   `json:"endDate,omitempty"`
+	Duration      string   `json:"duration,omitempty"`
+	Genre         []string `json:"genre,omitempty"`
+}
+
+// marshalJSONLD converts a Meta to JSON-LD with the matching schema.org type.
+func marshalJSONLD(meta *pb.Meta) ([]byte, error) {
+	imdbURL := "https://www.imdb.com/title/" + meta.Id + "/"
+	schemaOrgType, ok := schemaOrgTypes[meta.TitleType]
+	if !ok {
+		schemaOrgType = "Movie"
+	}
+	ld := jsonLD{
+		Context:       "https://schema.org",
+		Type:          schemaOrgType,
+		ID:            imdbURL,
+		Name:          meta.PrimaryTitle,
+		AlternateName: meta.OriginalTitle,
+		SameAs:        imdbURL,
+		Genre:         meta.Genres,
+	}
+	// ISO 8601 allows dates with only the year
+	if meta.StartYear != 0 {
+		ld.DatePublished = strconv.Itoa(int(meta.StartYear))
+		if schemaOrgType == "TVSeries" || schemaOrgType == "RadioSeries" {
+			ld.StartDate = ld.DatePublished
+			if meta.EndYear != 0 {
+				ld.EndDate = strconv.Itoa(int(meta.EndYear))
+			}
+		}
+	}
+	if meta.Runtime != 0 {
+		ld.Duration = isoDuration(int(meta.Runtime))
+	}
+	return json.Marshal(ld)
+}
+
+// isoDuration formats minutes as ISO 8601 duration, like "PT2H55M" for 175 minutes.
+func isoDuration(minutes int) string {
+	duration := "PT"
+	if minutes >= 60 {
+		duration += strconv.Itoa(minutes/60) + "H"
+	}
+	if minutes%60 != 0 {
+		duration += strconv.Itoa(minutes%60) + "M"
+	}
+	return duration
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// runGroup runs actors like the HTTP and gRPC server until the first one returns, for example due to an error or a signal, and then stops all of them.
+// It's similar to github.com/oklog/run, but with a shared deadline for stopping the actors.
+type runGroup struct {
+	actors []actor
+}
+
+type actor struct {
+	name string
+	// run blocks until the actor is stopped or fails
+	run func() error
+	// stop makes run return, gracefully until the context is done
+	stop func(ctx context.Context) error
+}
+
+func (g *runGroup) add(name string, run func() error, stop func(ctx context.Context) error) {
+	g.actors = append(g.actors, actor{name: name, run: run, stop: stop})
+}
+
+// run starts all actors and waits until the first one returns. Then it stops all actors in the order in which they were added,
+// and waits until all of them returned, with the shutdown timeout for all of this together.
+// It returns the error of the first actor as reason for stopping, and the errors that occurred while stopping.
+func (g *runGroup) run(shutdownTimeout time.Duration) (reason error, err error) {
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(g.actors))
+	for _, a := range g.actors {
+		go func(a actor) {
+			results <- result{name: a.name, err: a.run()}
+		}(a)
+	}
+
+	first := <-results
+	if first.err != nil {
+		reason = fmt.Errorf("%v: %w", first.name, first.err)
+	} else {
+		reason = fmt.Errorf("%v stopped unexpectedly", first.name)
+	}
+	log.Printf("Stopping all servers: %v\n", reason)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	var errs []error
+	for _, a := range g.actors {
+		if err := a.stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't stop %v: %w", a.name, err))
+		}
+	}
+	for i := 1; i < len(g.actors); i++ {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				errs = append(errs, fmt.Errorf("%v: %w", r.name, r.err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("not all servers stopped within the shutdown timeout: %w", ctx.Err()))
+			return reason, errors.Join(errs...)
+		}
+	}
+	return reason, errors.Join(errs...)
+}
+
+// signalError is the reason for stopping when the process received a signal.
+type signalError struct {
+	signal os.Signal
+}
+
+func (e signalError) Error() string {
+	return fmt.Sprintf("received signal %v", e.signal)
+}
+
+// addSignalActor adds an actor that returns a signalError when the process receives one of the signals.
+func (g *runGroup) addSignalActor(signals ...os.Signal) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, signals...)
+	stopped := make(chan struct{})
+	g.add("signal handler", func() error {
+		select {
+		case sig := <-c:
+			return signalError{signal: sig}
+		case <-stopped:
+			return nil
+		}
+	}, func(context.Context) error {
+		signal.Stop(c)
+		close(stopped)
+		return nil
+	})
+}
+
+// readiness is the state whether the service is ready to serve requests, which is reported via the HTTP "/ready" endpoint,
+// the gRPC health service and to systemd.
+type readiness struct {
+	// Guards the transitions, so that the service can't become ready after it started stopping
+	mu           sync.Mutex
+	ready        bool
+	stopping     bool
+	healthServer *health.Server
+	// gRPC services whose health is reported, in addition to the overall health with the empty service name
+	services []string
+}
+
+func newReadiness(services ...string) *readiness {
+	r := &readiness{
+		healthServer: health.NewServer(),
+		services:     append([]string{""}, services...),
+	}
+	for _, service := range r.services {
+		r.healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return r
+}
+
+func (r *readiness) isReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready
+}
+
+// addActor adds an actor that sets the state to ready when the group runs, and to not ready when the group stops.
+// It should be added first, so that clients stop sending requests as early as possible.
+func (r *readiness) addActor(g *runGroup) {
+	stopped := make(chan struct{})
+	g.add("readiness", func() error {
+		r.setReady()
+		<-stopped
+		return nil
+	}, func(context.Context) error {
+		r.setStopping()
+		close(stopped)
+		return nil
+	})
+}
+
+func (r *readiness) setReady() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopping {
+		return
+	}
+	r.ready = true
+	for _, service := range r.services {
+		r.healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	log.Println("Service is ready")
+	if err := sdNotify("READY=1"); err != nil {
+		log.Printf("Couldn't notify systemd about readiness: %v\n", err)
+	}
+}
+
+func (r *readiness) setStopping() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready = false
+	r.stopping = true
+	// Sets all services to NOT_SERVING and ignores later changes
+	r.healthServer.Shutdown()
+	if err := sdNotify("STOPPING=1"); err != nil {
+		log.Printf("Couldn't notify systemd about stopping: %v\n", err)
+	}
+}
+
+// waitContext calls f and waits until it returns or the context is done, for functions that don't support contexts.
+func waitContext(ctx context.Context, f func() error) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- f()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		// f might have returned at the same time, for example when the context was already done before
+		select {
+		case err := <-errc:
+			return err
+		default:
+			return ctx.Err()
+		}
+	}
+}
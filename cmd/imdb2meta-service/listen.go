@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// listen creates a listener on the Unix domain socket at socketPath if it's set, otherwise on the TCP address.
+// A socket file that's left over from a previous run is removed, unless another process still listens on it.
+func listen(network, addr, socketPath string) (net.Listener, error) {
+	if socketPath == "" {
+		return net.Listen(network, addr)
+	}
+	if fileInfo, err := os.Lstat(socketPath); err == nil && fileInfo.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", socketPath); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("another process listens on %v", socketPath)
+		}
+		if err = os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("couldn't remove existing socket file: %w", err)
+		}
+	}
+	// The socket file is removed when the listener is closed
+	return net.Listen("unix", socketPath)
+}
+
+// systemdListeners returns the listeners that systemd passed to the process with socket activation, see sd_listen_fds(3).
+// Listeners with the name "http" or "grpc" (set via FileDescriptorName= in the socket unit) are returned for the respective server.
+// Without names, the first listener is for HTTP and the second one for gRPC, in the order of the ListenStream= directives.
+// Both are nil if the process wasn't socket-activated.
+func systemdListeners() (httpLis, grpcLis net.Listener, err error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	// The variables must not be inherited by child processes
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	var unnamed []net.Listener
+	for i := 0; i < n; i++ {
+		// The file descriptors start after stdin, stdout and stderr
+		fd := 3 + i
+		syscall.CloseOnExec(fd)
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		lis, err := net.FileListener(f)
+		// The listener has its own copy of the file descriptor
+		_ = f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't use file descriptor %v (%v) as listener: %w", fd, name, err)
+		}
+		switch name {
+		case "http":
+			httpLis = lis
+		case "grpc":
+			grpcLis = lis
+		default:
+			unnamed = append(unnamed, lis)
+		}
+	}
+	for _, lis := range unnamed {
+		if httpLis == nil {
+			httpLis = lis
+		} else if grpcLis == nil {
+			grpcLis = lis
+		} else {
+			_ = lis.Close()
+		}
+	}
+	return httpLis, grpcLis, nil
+}
+
+// sdNotify sends a message like "READY=1" to the service manager, see sd_notify(3). Without the NOTIFY_SOCKET environment variable (set by systemd for Type=notify) it does nothing.
+func sdNotify(state string) error {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return nil
+	}
+	// Go handles the "@" prefix of abstract sockets
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return err
+	}
+	return nil
+}
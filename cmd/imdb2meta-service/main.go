@@ -1,26 +1,33 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"log"
-	"net"
-	"net/http"
 	"os"
-	"os/signal"
+	"runtime"
 	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/klauspost/compress/zstd"
 	"go.etcd.io/bbolt"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/index"
 	"github.com/deflix-tv/imdb2meta/pb"
+	"github.com/deflix-tv/imdb2meta/staticdb"
 )
 
 var (
@@ -28,12 +35,40 @@ var (
 	httpPort = flag.Int("httpPort", 8080, "Port to listen on for HTTP requests")
 	grpcPort = flag.Int("grpcPort", 8081, "Port to listen on for gRPC requests")
 
+	singlePort = flag.Bool("singlePort", false, "Serve HTTP/1.1, h2c (HTTP/2 without TLS) and gRPC on the HTTP port. The gRPC port isn't used then.")
+	httpSocket = flag.String("httpSocket", "", "Path of a Unix domain socket to listen on for HTTP requests instead of -bindAddr and -httpPort")
+	grpcSocket = flag.String("grpcSocket", "", "Path of a Unix domain socket to listen on for gRPC requests instead of -bindAddr and -grpcPort")
+
+	shutdownTimeout = flag.Duration("shutdownTimeout", 9*time.Second, "Maximum duration of the graceful shutdown, in which the servers wait for the current requests to finish. If requests take longer, the service exits without waiting for them, so their connections are closed without a response.")
+
 	badgerPath = flag.String("badgerPath", "", "Path to the directory with the BadgerDB files")
 	boltPath   = flag.String("boltPath", "", "Path to the bbolt DB file")
+	staticPath = flag.String("staticPath", "", "Path to the static DB file")
+
+	maxBatchSize  = flag.Int("maxBatchSize", 200, "Maximum number of IDs in a batch request")
+	jsonCacheSize = flag.Int("jsonCacheSize", 0, "Number of JSON responses to cache, so that the HTTP handler can send them without converting the protocol buffer to JSON. 0 disables the cache.")
+	indexPath     = flag.String("indexPath", "", "Path to the title index file that's created by the importer. Required for searching titles.")
+	inMemory      = flag.Bool("inMemory", false, "Load all data from the DB into memory at startup and serve all requests from memory. The DB is closed after loading.")
+	omdb          = flag.Bool("omdb", false, "Provide an OMDb-compatible API at \"/omdb\". Searching titles requires a title index.")
+	nfoID         = flag.String("nfo", "", "IMDb ID of a title whose NFO file (for Kodi and Jellyfin) is written to stdout. The servers aren't started in this mode.")
+	graphQL       = flag.Bool("graphql", false, "Provide a GraphQL API at \"/graphql\". Searching titles requires a title index.")
+	grpcWeb       = flag.Bool("grpcWeb", false, "Accept gRPC-Web and Connect requests for the gRPC service on the HTTP port, for example from browsers")
+	stremio       = flag.Bool("stremio", false, "Act as Stremio addon, with the manifest at \"/manifest.json\". With a title index the addon also provides catalogs.")
+
+	// Defaults for the query parameters of the JSON marshal options middleware
+	jsonEmitDefaults = flag.Bool("jsonEmitDefaults", false, `Include fields with default values, like "startYear": 0, in JSON responses. Requests can override this with the "emitDefaults" query parameter.`)
+	jsonFieldNames   = flag.String("jsonFieldNames", "camel", `Field names in JSON responses, "camel" (like "primaryTitle") or "snake" (like "primary_title"). Requests can override this with the "fieldNames" query parameter.`)
+	jsonEnumsAsInts  = flag.Bool("jsonEnumsAsInts", false, `Use the numbers instead of the names of enum values, like for the title type, in JSON responses. Requests can override this with the "enumsAsInts" query parameter.`)
+	jsonPretty       = flag.Bool("jsonPretty", false, `Indent JSON responses. Requests can override this with the "pretty" query parameter.`)
 )
 
 var (
-	imdbBytes = []byte("imdb") // Bucket name for bbolt
+	imdbBytes      = []byte("imdb")     // Bucket name for bbolt
+	metadataBytes  = []byte("metadata") // Bucket name for metadata in bbolt
+	metadataPrefix = "metadata:"        // Key prefix for metadata in BadgerDB
+	keyEncodingKey = "keyEncoding"      // Metadata key for the key encoding
+	zstdDictKey    = "zstdDict"         // Metadata key for the zstd dictionary
+	zstdMagic      = []byte{0x28, 0xb5, 0x2f, 0xfd}
 )
 
 func main() {
@@ -46,10 +81,22 @@ func main() {
 	flag.Parse()
 
 	// CLI argument check
-	if *badgerPath == "" && *boltPath == "" {
-		log.Fatalln(`Missing an argument for the DB: Either "-badgerPath" or "-boltPath".`)
-	} else if *badgerPath != "" && *boltPath != "" {
-		log.Fatalln(`You can only use either "-badgerPath" or "-boltPath", but not both at the same time`)
+	dbArgs := 0
+	for _, dbPath := range []string{*badgerPath, *boltPath, *staticPath} {
+		if dbPath != "" {
+			dbArgs++
+		}
+	}
+	if dbArgs == 0 {
+		log.Fatalln(`Missing an argument for the DB: Either "-badgerPath", "-boltPath" or "-staticPath".`)
+	} else if dbArgs > 1 {
+		log.Fatalln(`You can only use one of "-badgerPath", "-boltPath" and "-staticPath", but not multiple at the same time`)
+	}
+	if *inMemory && *staticPath != "" {
+		log.Fatalln(`"-inMemory" can't be used with "-staticPath", because the static DB file is already memory-mapped`)
+	}
+	if *jsonFieldNames != "camel" && *jsonFieldNames != "snake" {
+		log.Fatalln(`"-jsonFieldNames" must be "camel" or "snake"`)
 	}
 
 	// Set up DB
@@ -57,21 +104,45 @@ func main() {
 	log.Println("Setting up DB...")
 	var badgerDB *badger.DB
 	var boltDB *bbolt.DB
+	var staticDB *staticdb.DB
 	var err error
-	if *badgerPath != "" {
+	// When the shutdown times out, handlers might still be reading from the DB, so it must not be closed.
+	// Especially the static DB, because reading from its unmapped memory would crash the process.
+	shutdownTimedOut := false
+	if *staticPath != "" {
+		staticDB, err = staticdb.Open(*staticPath)
+		if err != nil {
+			log.Fatalf("Couldn't open static DB: %v\n", err)
+		}
+		defer func() {
+			if !shutdownTimedOut {
+				staticDB.Close()
+			}
+		}()
+		log.Printf("Opened static DB with format version %v, dataset date %v and %v objects\n", staticDB.Version(), staticDB.DatasetDate().Format("2006-01-02"), staticDB.Len())
+	} else if *badgerPath != "" {
 		opts := badger.DefaultOptions(*badgerPath).
 			WithLoggingLevel(badger.WARNING)
 		badgerDB, err = badger.Open(opts)
 		if err != nil {
 			log.Fatalf("Couldn't open BadgerDB: %v\n", err)
 		}
-		defer badgerDB.Close()
+		// Closure instead of a direct deferred call, because in the in-memory mode the DB is already closed after loading the data
+		defer func() {
+			if badgerDB != nil && !shutdownTimedOut {
+				badgerDB.Close()
+			}
+		}()
 	} else {
 		boltDB, err = bbolt.Open(*boltPath, 0666, nil)
 		if err != nil {
 			log.Fatalf("Couldn't open bbolt DB: %v\n", err)
 		}
-		defer boltDB.Close()
+		defer func() {
+			if boltDB != nil && !shutdownTimedOut {
+				boltDB.Close()
+			}
+		}()
 		err = boltDB.View(func(tx *bbolt.Tx) error {
 			if tx.Bucket(imdbBytes) == nil {
 				return errors.New(`bbolt bucket "imdb" doesn't exist`)
@@ -83,13 +154,122 @@ func main() {
 		}
 	}
 
+	// Here after we have opened the DB, don't use log.Fatal or os.Exit, as then the DB won't be closed and can end up in a corrupted state.
+	// So we log with Print and then return, leading to the deferred DB close and then deferred os.Exit(1) being called.
+
+	keyEncBytes, err := getMetadata(badgerDB, boltDB, staticDB, keyEncodingKey)
+	if err != nil {
+		log.Printf("Couldn't read key encoding from DB: %v\n", err)
+		return
+	}
+	// DBs from older importer versions don't have the metadata, but always use the string encoding
+	keyEnc := imdbid.KeyEncodingString
+	if keyEncBytes != nil {
+		if keyEnc, err = imdbid.ParseKeyEncoding(string(keyEncBytes)); err != nil {
+			log.Printf("Invalid key encoding in DB: %v\n", err)
+			return
+		}
+	}
+	log.Printf("Using key encoding %q\n", keyEnc)
+
+	// The values are compressed if the importer stored a zstd dictionary
+	zstdDict, err := getMetadata(badgerDB, boltDB, staticDB, zstdDictKey)
+	if err != nil {
+		log.Printf("Couldn't read zstd dictionary from DB: %v\n", err)
+		return
+	}
+	var decoder *zstd.Decoder
+	if zstdDict != nil {
+		log.Println("The values in the DB are compressed, decompressing them with the stored zstd dictionary")
+		// DecodeAll is safe for concurrent use, but limited to as many concurrent calls as the decoder concurrency.
+		// 0 means GOMAXPROCS instead of the default of at most 4, because every request decodes values.
+		decoder, err = zstd.NewReader(nil, zstd.WithDecoderDicts(zstdDict), zstd.WithDecoderConcurrency(0))
+		if err != nil {
+			log.Printf("Couldn't create zstd decoder: %v\n", err)
+			return
+		}
+		defer func() {
+			if !shutdownTimedOut {
+				decoder.Close()
+			}
+		}()
+	}
+
 	metaStore := &metaStore{
-		badgerDB: badgerDB,
-		boltDB:   boltDB,
+		badgerDB:    badgerDB,
+		boltDB:      boltDB,
+		staticDB:    staticDB,
+		keyEncoding: keyEnc,
+		decoder:     decoder,
 	}
 
-	// Here after we have opened the DB, don't use log.Fatal or os.Exit, as then the DB won't be closed and can end up in a corrupted state.
-	// So we log with Print and then return, leading to the deferred DB close and then deferred os.Exit(1) being called.
+	if *nfoID != "" {
+		metaBytes, err := metaStore.Get(*nfoID)
+		if err != nil {
+			log.Printf("Couldn't get data from DB: %v\n", err)
+			return
+		}
+		meta := &pb.Meta{}
+		if err = proto.Unmarshal(metaBytes, meta); err != nil {
+			log.Printf("Couldn't unmarshal protocol buffer into object: %v\n", err)
+			return
+		}
+		nfoXML, err := marshalNFO(meta)
+		if err != nil {
+			log.Printf("Couldn't marshal object into NFO: %v\n", err)
+			return
+		}
+		if _, err = os.Stdout.Write(nfoXML); err != nil {
+			log.Printf("Couldn't write NFO: %v\n", err)
+			return
+		}
+		exitCode = 0
+		return
+	}
+
+	if *inMemory {
+		log.Println("Loading all data into memory...")
+		var memStatsBefore, memStatsAfter runtime.MemStats
+		runtime.ReadMemStats(&memStatsBefore)
+		start := time.Now()
+		memStore, err := loadMemStore(badgerDB, boltDB)
+		if err != nil {
+			log.Printf("Couldn't load data into memory: %v\n", err)
+			return
+		}
+		duration := time.Since(start)
+		// Get rid of the garbage from loading, so the heap stats reflect the actual footprint
+		runtime.GC()
+		runtime.ReadMemStats(&memStatsAfter)
+		log.Printf("Loaded %v objects into memory in %v. Data size: %.1f MB, heap growth: %.1f MB\n",
+			memStore.Len(), duration, float64(memStore.Size())/1e6, (float64(memStatsAfter.HeapAlloc)-float64(memStatsBefore.HeapAlloc))/1e6)
+		metaStore.memStore = memStore
+		metaStore.badgerDB = nil
+		metaStore.boltDB = nil
+		// All data is in memory now, so we don't need the DB anymore
+		if badgerDB != nil {
+			err = badgerDB.Close()
+			badgerDB = nil
+		} else {
+			err = boltDB.Close()
+			boltDB = nil
+		}
+		if err != nil {
+			log.Printf("Couldn't close DB: %v\n", err)
+			return
+		}
+	}
+
+	var titleIndex *index.Index
+	if *indexPath != "" {
+		log.Println("Loading title index...")
+		titleIndex, err = index.Load(*indexPath)
+		if err != nil {
+			log.Printf("Couldn't load title index: %v\n", err)
+			return
+		}
+		log.Printf("Loaded title index with %v titles\n", titleIndex.Len())
+	}
 
 	// Set up HTTP service
 
@@ -118,97 +298,170 @@ func main() {
 	app.Use(logger.New())
 	// Endpoints
 	app.Get("/health", healthHandler)
-	app.Get("/meta/:id", createMetaHandler(metaStore))
-
-	// Start HTTP server
-
-	log.Println("Starting HTTP server...")
-	stopping := false
-	stoppingPtr := &stopping
-	addr := *bindAddr + ":" + strconv.Itoa(*httpPort)
-	listenErr := make(chan struct{})
-	go func() {
-		if err := app.Listen(addr); err != nil {
-			if !*stoppingPtr {
-				log.Printf("Couldn't start HTTP server: %v\n", err)
-				close(listenErr)
-			} else {
-				log.Printf("Error in app.Listen() during HTTP server shutdown (probably context deadline expired before the server could shutdown cleanly): %v\n", err)
-				close(listenErr)
-			}
-		}
-	}()
-	// Send HTTP request to the health endpoint to ensure the server was started successfully
-	time.Sleep(time.Second)
-	healthURL := "http://"
-	if *bindAddr == "0.0.0.0" {
-		healthURL += "localhost"
-	} else {
-		healthURL += *bindAddr
+	serviceReadiness := newReadiness(pb.MetaFetcher_ServiceDesc.ServiceName)
+	app.Get("/ready", createReadyHandler(serviceReadiness))
+	var metaJSONCache *jsonCache
+	if *jsonCacheSize > 0 {
+		metaJSONCache = newJSONCache(*jsonCacheSize)
 	}
-	healthURL += ":" + strconv.Itoa(*httpPort) + "/health"
-	_, err = http.Get(healthURL) // Note: No timeout by default
-	if err != nil {
-		log.Printf("Couldn't send test request to HTTP server: %v\n", err)
+	defaultMarshalOptions := protojson.MarshalOptions{
+		Multiline:       *jsonPretty,
+		UseProtoNames:   *jsonFieldNames == "snake",
+		UseEnumNumbers:  *jsonEnumsAsInts,
+		EmitUnpopulated: *jsonEmitDefaults,
+	}
+	if *jsonPretty {
+		defaultMarshalOptions.Indent = "    "
+	}
+	// Only for the endpoints that respond with protocol buffer messages
+	marshalOptionsHandler := createMarshalOptionsMiddleware(defaultMarshalOptions)
+	// Must be registered before "/meta/:id", which would match as well
+	app.Get("/meta/:id.nfo", createNFOHandler(metaStore))
+	app.Get("/meta/:id", marshalOptionsHandler, createMetaHandler(metaStore, metaJSONCache, defaultMarshalOptions))
+	// The gRPC server's implementation is used for the REST routes of the RPCs as well, and for the legacy routes, which are aliases of them
+	metaServer := createGRPCserver(metaStore, *maxBatchSize, titleIndex)
+	if err := registerLegacyRoutes(app, &pb.MetaFetcher_ServiceDesc, metaServer, legacyRoutes, marshalOptionsHandler); err != nil {
+		log.Printf("Couldn't register legacy routes: %v\n", err)
 		return
 	}
-	// Check listenErr additionally, because the health request could otherwise be successful for example due to an already running service on the same port.
-	select {
-	case <-listenErr:
+	if err := registerTranscodedRoutes(app, &pb.MetaFetcher_ServiceDesc, metaServer, marshalOptionsHandler); err != nil {
+		log.Printf("Couldn't register transcoded routes: %v\n", err)
 		return
-	case <-time.After(time.Second):
-		log.Println("HTTP server started successfully!")
+	}
+	if *grpcWeb {
+		// Browsers send preflight requests due to the content types, and can only read the status headers of "trailers-only" responses when they're exposed
+		webCORSHandler := cors.New(cors.Config{
+			ExposeHeaders: "Grpc-Status,Grpc-Message",
+		})
+		app.Options("/"+pb.MetaFetcher_ServiceDesc.ServiceName+"/:method", webCORSHandler)
+		if err := registerWebRoutes(app, &pb.MetaFetcher_ServiceDesc, metaServer, webCORSHandler); err != nil {
+			log.Printf("Couldn't register gRPC-Web and Connect routes: %v\n", err)
+			return
+		}
+	}
+	if *graphQL {
+		schema, err := newGraphQLSchema(metaStore, titleIndex, *maxBatchSize)
+		if err != nil {
+			log.Printf("Couldn't create GraphQL schema: %v\n", err)
+			return
+		}
+		graphQLHandler := createGraphQLHandler(schema, metaStore, *maxBatchSize)
+		app.Get("/graphql", graphQLHandler)
+		app.Post("/graphql", graphQLHandler)
+	}
+	if *omdb {
+		app.Get("/omdb", createOMDbHandler(metaStore, titleIndex))
+	}
+	if *stremio {
+		manifestHandler, err := createStremioManifestHandler(titleIndex)
+		if err != nil {
+			log.Printf("Couldn't create Stremio manifest: %v\n", err)
+			return
+		}
+		// Stremio runs in browsers as well, so the addon must allow cross-origin requests.
+		// The middleware is only added to the addon's routes, because a group without prefix would add it to all routes.
+		corsHandler := cors.New()
+		app.Get("/manifest.json", corsHandler, manifestHandler)
+		app.Get("/meta/:type/:id", corsHandler, createStremioMetaHandler(metaStore))
+		if titleIndex != nil {
+			catalogHandler := createStremioCatalogHandler(metaStore, titleIndex)
+			app.Get("/catalog/:type/:id", corsHandler, catalogHandler)
+			app.Get("/catalog/:type/:id/:extra", corsHandler, catalogHandler)
+		}
 	}
 
-	// Start gRPC server
+	// Create gRPC server, which is served by the multiplexer in single-port mode
 
-	addr = *bindAddr + ":" + strconv.Itoa(*grpcPort)
-	lis, err := net.Listen("tcp", addr)
-	if err != nil {
-		log.Printf("failed to listen: %v", err)
-		return
-	}
 	s := grpc.NewServer()
-	metaServer := createGRPCserver(metaStore)
 	pb.RegisterMetaFetcherServer(s, metaServer)
+	healthpb.RegisterHealthServer(s, serviceReadiness.healthServer)
 	// Register reflection service on gRPC server for dynamic clients to discover services and types.
 	reflection.Register(s)
-	go func() {
-		if err := s.Serve(lis); err != nil {
-			log.Printf("Failed to serve gRPC: %v\n", err)
-			// TODO: Check if there are cases where the channel could already be closed
-			close(listenErr)
-		}
-	}()
-	select {
-	case <-listenErr:
+
+	// Create listeners. Listeners from systemd socket activation take precedence over Unix domain sockets, which take precedence over TCP.
+	// When the listeners are created, the servers can't fail to start anymore, because connections are queued until the servers accept them.
+
+	httpLis, grpcLis, err := systemdListeners()
+	if err != nil {
+		log.Printf("Couldn't use listeners from systemd socket activation: %v\n", err)
 		return
-	case <-time.After(time.Second):
-		log.Println("gRPC server started successfully!")
+	} else if httpLis != nil || grpcLis != nil {
+		log.Println("Using listeners from systemd socket activation")
+	}
+	if httpLis == nil {
+		if httpLis, err = listen("tcp4", *bindAddr+":"+strconv.Itoa(*httpPort), *httpSocket); err != nil {
+			log.Printf("Couldn't listen for HTTP requests: %v\n", err)
+			return
+		}
+	}
+	if *singlePort {
+		if grpcLis != nil {
+			_ = grpcLis.Close()
+			grpcLis = nil
+		}
+	} else if grpcLis == nil {
+		if grpcLis, err = listen("tcp", *bindAddr+":"+strconv.Itoa(*grpcPort), *grpcSocket); err != nil {
+			_ = httpLis.Close()
+			log.Printf("Couldn't listen for gRPC requests: %v\n", err)
+			return
+		}
 	}
 
-	// Graceful shutdown
+	// Run servers until a signal is received or a server fails.
+	// When stopping, the service first reports not being ready, then the servers stop accepting new requests and wait for the current ones to finish.
 
-	c := make(chan os.Signal, 1)
+	var group runGroup
+	serviceReadiness.addActor(&group)
+	httpServedLis := httpLis
+	var mux *multiplexer
+	if *singlePort {
+		mux = newMultiplexer(httpLis, app, s)
+		httpServedLis = mux.http1Listener
+	}
+	log.Printf("Starting HTTP server on %v...\n", httpLis.Addr())
+	group.add("HTTP server", func() error {
+		return app.Listener(httpServedLis)
+	}, func(ctx context.Context) error {
+		err := waitContext(ctx, app.Shutdown)
+		// Fiber's shutdown doesn't close the listener if the server didn't start serving yet
+		_ = httpServedLis.Close()
+		return err
+	})
+	if mux != nil {
+		log.Println("gRPC server is served on the HTTP port")
+		// HTTP/2 connections, including the ones for gRPC requests, are shut down after the HTTP/1.1 connections, so the Fiber app can close the listener
+		group.add("multiplexer", mux.serve, mux.shutdown)
+	} else {
+		log.Printf("Starting gRPC server on %v...\n", grpcLis.Addr())
+		group.add("gRPC server", func() error {
+			return s.Serve(grpcLis)
+		}, func(ctx context.Context) error {
+			err := waitContext(ctx, func() error {
+				s.GracefulStop()
+				return nil
+			})
+			if err != nil {
+				// Cancels the active RPCs
+				s.Stop()
+			}
+			return err
+		})
+	}
 	// Accept SIGINT (Ctrl+C) and SIGTERM (`docker stop`)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	sig := <-c
-	log.Printf("Received signal %v, shutting down HTTP and gRPC server...\n", sig)
-	*stoppingPtr = true
-	// Graceful shutdown, waiting for all current requests to finish without accepting new ones.
-	httpShutdownErr := false
-	if err := app.Shutdown(); err != nil {
-		log.Printf("Error shutting down HTTP server: %v\n", err)
-		httpShutdownErr = true
-	}
-	s.GracefulStop()
-	if httpShutdownErr {
+	group.addSignalActor(os.Interrupt, syscall.SIGTERM)
+
+	reason, err := group.run(*shutdownTimeout)
+	if err != nil {
+		log.Printf("Error shutting down HTTP and gRPC server: %v\n", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			shutdownTimedOut = true
+			log.Println("Exiting without closing the DB, because requests might still be running")
+		}
 		return
 	}
 	log.Println("Finished shutting down HTTP and gRPC server")
-	select {
-	case <-listenErr:
-	default:
+	// Only stopping due to a signal is regular
+	if errors.As(reason, &signalError{}) {
 		exitCode = 0
 	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/dgraph-io/badger/v2"
+	"go.etcd.io/bbolt"
+)
+
+// memStore is a read-only in-memory copy of all key-value pairs of a DB.
+// Instead of a map with millions of small allocations, all keys and all values are stored in one contiguous byte slice each,
+// sorted by key, with offsets into them. This keeps the memory overhead per entry at 8 bytes and lookups are binary searches without any allocations.
+type memStore struct {
+	keys       []byte
+	keyOffsets []uint32 // Start of the i-th key in keys. Contains one more element than there are entries, so the end of the i-th key is keyOffsets[i+1].
+	values     []byte
+	valOffsets []uint32 // Same as keyOffsets, but for values
+}
+
+// loadMemStore reads all key-value pairs from the given DB (one of them must be non-nil) into a new memStore.
+func loadMemStore(badgerDB *badger.DB, boltDB *bbolt.DB) (*memStore, error) {
+	s := &memStore{
+		keyOffsets: []uint32{0},
+		valOffsets: []uint32{0},
+	}
+	// Both DBs iterate in lexicographic key order, so we can just append and end up with sorted slices.
+	add := func(k, v []byte) error {
+		if uint64(len(s.keys))+uint64(len(k)) > math.MaxUint32 || uint64(len(s.values))+uint64(len(v)) > math.MaxUint32 {
+			return errors.New("data is too big for the in-memory store")
+		}
+		s.keys = append(s.keys, k...)
+		s.keyOffsets = append(s.keyOffsets, uint32(len(s.keys)))
+		s.values = append(s.values, v...)
+		s.valOffsets = append(s.valOffsets, uint32(len(s.values)))
+		return nil
+	}
+
+	var err error
+	if badgerDB != nil {
+		err = badgerDB.View(func(txn *badger.Txn) error {
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				item := it.Item()
+				if hasMetadataPrefix(item.Key()) {
+					continue
+				}
+				err := item.Value(func(val []byte) error {
+					return add(item.Key(), val)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	} else {
+		err = boltDB.View(func(tx *bbolt.Tx) error {
+			return tx.Bucket(imdbBytes).ForEach(add)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the spare capacity from growing the slices
+	s.keys = append([]byte(nil), s.keys...)
+	s.keyOffsets = append([]uint32(nil), s.keyOffsets...)
+	s.values = append([]byte(nil), s.values...)
+	s.valOffsets = append([]uint32(nil), s.valOffsets...)
+
+	return s, nil
+}
+
+// Get returns the value for the given key. The returned slice points into the store's memory and must not be modified.
+func (s *memStore) Get(key string) ([]byte, error) {
+	count := s.Len()
+	i := sort.Search(count, func(i int) bool {
+		return string(s.key(i)) >= key
+	})
+	if i == count || string(s.key(i)) != key {
+		return nil, errNotFound
+	}
+	return s.values[s.valOffsets[i]:s.valOffsets[i+1]], nil
+}
+
+// Len returns the number of entries in the store.
+func (s *memStore) Len() int {
+	return len(s.keyOffsets) - 1
+}
+
+// Size returns the number of bytes occupied by the store's data.
+func (s *memStore) Size() int {
+	return len(s.keys) + len(s.values) + 4*len(s.keyOffsets) + 4*len(s.valOffsets)
+}
+
+func (s *memStore) key(i int) []byte {
+	return s.keys[s.keyOffsets[i]:s.keyOffsets[i+1]]
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/dgraph-io/badger/v2"
+	"go.etcd.io/bbolt"
+
+	"github.com/deflix-tv/imdb2meta/staticdb"
+)
+
+// getMetadata reads a metadata value that the importer stored in the DB. One of the DBs must be non-nil.
+// Returns nil if the key doesn't exist.
+func getMetadata(badgerDB *badger.DB, boltDB *bbolt.DB, staticDB *staticdb.DB, key string) ([]byte, error) {
+	var value []byte
+	var err error
+	if staticDB != nil {
+		value, _ = staticDB.Metadata(key)
+	} else if badgerDB != nil {
+		err = badgerDB.View(func(txn *badger.Txn) error {
+			item, err := txn.Get([]byte(metadataPrefix + key))
+			if err == badger.ErrKeyNotFound {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			value, err = item.ValueCopy(nil)
+			return err
+		})
+	} else {
+		err = boltDB.View(func(tx *bbolt.Tx) error {
+			// DBs from older importer versions don't have the bucket
+			bucket := tx.Bucket(metadataBytes)
+			if bucket == nil {
+				return nil
+			}
+			if txBytes := bucket.Get([]byte(key)); txBytes != nil {
+				value = append([]byte(nil), txBytes...)
+			}
+			return nil
+		})
+	}
+	return value, err
+}
+
+func hasMetadataPrefix(key []byte) bool {
+	return len(key) >= len(metadataPrefix) && string(key[:len(metadataPrefix)]) == metadataPrefix
+}
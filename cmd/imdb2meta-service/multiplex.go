@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/soheilhy/cmux"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+)
+
+// multiplexer serves HTTP/1.1, h2c (HTTP/2 without TLS) and gRPC on a single listener.
+//
+// Connections are distinguished by the HTTP/2 connection preface. HTTP/1.1 connections are served by the Fiber app via http1Listener.
+// HTTP/2 connections are served by a net/http HTTP/2 server, which routes gRPC requests to the gRPC server and all other requests to the Fiber app.
+// Routing the requests instead of the connections allows clients to use the same HTTP/2 connection for both.
+type multiplexer struct {
+	lis           net.Listener
+	mux           cmux.CMux
+	http1Listener net.Listener
+	http2Listener net.Listener
+	http2Server   *http2.Server
+	// For the graceful shutdown of the HTTP/2 connections
+	baseServer *http.Server
+	http2Conns sync.WaitGroup
+	app        *fiber.App
+	grpcServer *grpc.Server
+}
+
+func newMultiplexer(lis net.Listener, app *fiber.App, grpcServer *grpc.Server) *multiplexer {
+	m := &multiplexer{
+		lis:         lis,
+		mux:         cmux.New(lis),
+		http2Server: &http2.Server{},
+		app:         app,
+		grpcServer:  grpcServer,
+	}
+	// The order determines the priority
+	m.http2Listener = m.mux.Match(cmux.HTTP2())
+	m.http1Listener = closedErrListener{m.mux.Match(cmux.Any())}
+	m.baseServer = &http.Server{Handler: http.HandlerFunc(m.serveHTTP2Request)}
+	// Registers the HTTP/2 server for the base server's shutdown
+	_ = http2.ConfigureServer(m.baseServer, m.http2Server)
+	return m
+}
+
+// serve accepts connections until the listener is closed. The Fiber app must serve http1Listener.
+func (m *multiplexer) serve() error {
+	go func() {
+		for {
+			conn, err := m.http2Listener.Accept()
+			if err != nil {
+				return
+			}
+			m.http2Conns.Add(1)
+			go func() {
+				defer m.http2Conns.Done()
+				m.http2Server.ServeConn(conn, &http2.ServeConnOpts{BaseConfig: m.baseServer})
+			}()
+		}
+	}()
+	err := m.mux.Serve()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// shutdown closes the listener and waits until all HTTP/2 connections are closed, after the client received the responses of the active requests.
+// HTTP/1.1 connections are shut down by the Fiber app. The gRPC server is stopped as well, because it's only served via the HTTP/2 connections.
+func (m *multiplexer) shutdown(ctx context.Context) error {
+	// Without HTTP/2 connections there are no gRPC requests anymore
+	defer m.grpcServer.Stop()
+	m.mux.Close()
+	// Fiber's shutdown closes the listener as well, so it might be closed already
+	_ = m.lis.Close()
+	if err := m.baseServer.Shutdown(ctx); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		m.http2Conns.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *multiplexer) serveHTTP2Request(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") && !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web") {
+		m.grpcServer.ServeHTTP(w, r)
+		return
+	}
+	serveFiber(m.app, m.lis.Addr(), w, r)
+}
+
+// serveFiber handles a net/http request with the Fiber app. localAddr is the address of the listener that accepted the connection.
+func serveFiber(app *fiber.App, localAddr net.Addr, w http.ResponseWriter, r *http.Request) {
+	var req fasthttp.Request
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.RequestURI())
+	req.Header.SetHost(r.Host)
+	for key, values := range r.Header {
+		for i, value := range values {
+			// Only Set handles special headers like Content-Type
+			if i == 0 {
+				req.Header.Set(key, value)
+			} else {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+	// fasthttp limits the body size when reading HTTP/1.1 requests
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(app.Config().BodyLimit)))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "Couldn't read request body", http.StatusBadRequest)
+		}
+		return
+	}
+	req.SetBody(body)
+	// fasthttp uses its zero TCP address for a nil interface, but not for a nil *net.TCPAddr
+	var remoteAddr net.Addr
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		remoteAddr = addr
+	} else if _, ok := localAddr.(*net.UnixAddr); ok {
+		// Like fasthttp for HTTP/1.1 connections via Unix domain sockets, where the client usually doesn't have an address
+		remoteAddr = &net.UnixAddr{Name: r.RemoteAddr, Net: localAddr.Network()}
+	}
+
+	var ctx fasthttp.RequestCtx
+	ctx.Init(&req, remoteAddr, nil)
+	app.Handler()(&ctx)
+
+	ctx.Response.Header.VisitAll(func(key, value []byte) {
+		// net/http sets these itself, and HTTP/2 doesn't allow connection-specific headers
+		switch string(key) {
+		case fasthttp.HeaderContentLength, fasthttp.HeaderTransferEncoding, fasthttp.HeaderConnection:
+			return
+		}
+		w.Header().Add(string(key), string(value))
+	})
+	w.WriteHeader(ctx.Response.StatusCode())
+	if r.Method == http.MethodHead {
+		return
+	}
+	// Streamed responses must be flushed for each write, so that the client receives the messages immediately
+	if err := ctx.Response.BodyWriteTo(flushWriter{w}); err != nil {
+		log.Printf("Couldn't write HTTP/2 response body: %v\n", err)
+	}
+}
+
+// closedErrListener returns net.ErrClosed when the multiplexer is closed, which fasthttp recognizes as regular end of serving, unlike the multiplexer's own errors.
+type closedErrListener struct {
+	net.Listener
+}
+
+func (l closedErrListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if errors.Is(err, cmux.ErrServerClosed) || errors.Is(err, cmux.ErrListenerClosed) {
+		return nil, net.ErrClosed
+	}
+	return conn, err
+}
+
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if flusher, ok := fw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
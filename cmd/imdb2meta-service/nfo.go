@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/xml"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// NFO files as used by Kodi and Jellyfin, see https://kodi.wiki/view/NFO_files
+
+// nfoRootElements maps the title types to the root element of the NFO file. All other title types are movies.
+var nfoRootElements = map[pb.TitleType]string{
+	pb.TitleType_TV_SERIES:      "tvshow",
+	pb.TitleType_TV_MINI_SERIES: "tvshow",
+	pb.TitleType_TV_EPISODE:     "episodedetails",
+	pb.TitleType_EPISODE:        "episodedetails",
+}
+
+type nfo struct {
+	XMLName       xml.Name
+	Title         string      `xml:"title"`
+	OriginalTitle string      `xml:"originaltitle"`
+	Year          int32       `xml:"year,omitempty"`
+	Runtime       int32       `xml:"runtime,omitempty"`
+	Genres        []string    `xml:"genre"`
+	UniqueID      nfoUniqueID `xml:"uniqueid"`
+}
+
+type nfoUniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr"`
+	ID      string `xml:",chardata"`
+}
+
+// marshalNFO converts a Meta to the content of a Kodi-compatible NFO file.
+func marshalNFO(meta *pb.Meta) ([]byte, error) {
+	rootElement, ok := nfoRootElements[meta.TitleType]
+	if !ok {
+		rootElement = "movie"
+	}
+	// The original title is only set when it's different from the primary title, but Kodi expects it
+	originalTitle := meta.OriginalTitle
+	if originalTitle == "" {
+		originalTitle = meta.PrimaryTitle
+	}
+	nfoXML, err := xml.MarshalIndent(nfo{
+		XMLName:       xml.Name{Local: rootElement},
+		Title:         meta.PrimaryTitle,
+		OriginalTitle: originalTitle,
+		Year:          meta.StartYear,
+		Runtime:       meta.Runtime,
+		Genres:        meta.Genres,
+		UniqueID: nfoUniqueID{
+			Type:    "imdb",
+			Default: true,
+			ID:      meta.Id,
+		},
+	}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>`+"\n"), append(nfoXML, '\n')...), nil
+}
+
+// createNFOHandler creates a handler that responds with the NFO file of a title, like for "/meta/tt1254207.nfo".
+func createNFOHandler(metaStore *metaStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := strings.TrimSuffix(c.Params("id"), ".nfo")
+		if id == "" {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+
+		metaBytes, err := metaStore.Get(id)
+		if err != nil {
+			if err == errNotFound {
+				log.Printf("Key not found in DB: %v\n", err)
+				return c.SendStatus(fiber.StatusNotFound)
+			}
+			log.Printf("Couldn't get data from DB: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		meta := &pb.Meta{}
+		if err = proto.Unmarshal(metaBytes, meta); err != nil {
+			log.Printf("Couldn't unmarshal protocol buffer into object: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		nfoXML, err := marshalNFO(meta)
+		if err != nil {
+			log.Printf("Couldn't marshal object into NFO: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+		return c.Send(nfoXML)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// OMDb API, see http://www.omdbapi.com/
+
+const (
+	// OMDb returns 10 search results per page and at most 100 pages
+	omdbPageSize = 10
+	omdbMaxPage  = 100
+	// OMDb uses "N/A" for unknown values
+	omdbNA = "N/A"
+)
+
+// Error messages like the ones of OMDb, because some clients check them
+const (
+	omdbErrNoQuery    = "Something went wrong."
+	omdbErrInvalidID  = "Incorrect IMDb ID."
+	omdbErrNotFound   = "Movie not found!"
+	omdbErrInternal   = "Error getting data."
+	omdbErrNoIndex    = "Searching titles requires a title index."
+	omdbErrInvalidArg = "Invalid parameter value."
+)
+
+// omdbTypes maps the title types to OMDb's types. All other title types are movies.
+var omdbTypes = map[pb.TitleType]string{
+	pb.TitleType_TV_SERIES:      "series",
+	pb.TitleType_TV_MINI_SERIES: "series",
+	pb.TitleType_RADIO_SERIES:   "series",
+	pb.TitleType_TV_EPISODE:     "episode",
+	pb.TitleType_EPISODE:        "episode",
+	pb.TitleType_VIDEO_GAME:     "game",
+}
+
+type omdbTitle struct {
+	Title    string
+	Year     string
+	Runtime  string
+	Genre    string
+	ImdbID   string `json:"imdbID"`
+	Type     string
+	Response string
+}
+
+type omdbSearch struct {
+	Search       []omdbSearchResult
+	TotalResults string `json:"totalResults"`
+	Response     string
+}
+
+type omdbSearchResult struct {
+	Title  string
+	Year   string
+	ImdbID string `json:"imdbID"`
+	Type   string
+	Poster string
+}
+
+type omdbError struct {
+	Response string
+	Error    string
+}
+
+// createOMDbHandler creates a handler that accepts OMDb's query parameters and responds like OMDb,
+// with a title by its IMDb ID ("i"), a title by its exact title ("t") or search results ("s").
+// The year ("y") and type ("type") restrict the title and search results.
+// Other parameters like "apikey" and "plot" are ignored.
+// Like OMDb, the handler also responds with status 200 OK when no title was found.
+func createOMDbHandler(metaStore *metaStore, titleIndex *index.Index) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter, err := parseOMDbFilter(c)
+		if err != nil {
+			return c.JSON(omdbError{Response: "False", Error: omdbErrInvalidArg})
+		}
+
+		if id := c.Query("i"); id != "" {
+			if kind, _, err := imdbid.Parse(id); err != nil || kind != imdbid.KindTitle {
+				return c.JSON(omdbError{Response: "False", Error: omdbErrInvalidID})
+			}
+			return sendOMDbTitle(c, metaStore, id)
+		}
+		title, query := c.Query("t"), c.Query("s")
+		if title == "" && query == "" {
+			return c.JSON(omdbError{Response: "False", Error: omdbErrNoQuery})
+		} else if titleIndex == nil {
+			return c.JSON(omdbError{Response: "False", Error: omdbErrNoIndex})
+		}
+
+		if title != "" {
+			// OMDb only returns titles that match exactly, which are ranked first
+			results, _ := titleIndex.Search(title, filter, 0, 1)
+			if len(results) == 0 || results[0].Match != index.MatchExact {
+				return c.JSON(omdbError{Response: "False", Error: omdbErrNotFound})
+			}
+			return sendOMDbTitle(c, metaStore, results[0].ID)
+		}
+
+		page := 1
+		if pageParam := c.Query("page"); pageParam != "" {
+			if page, err = strconv.Atoi(pageParam); err != nil || page < 1 || page > omdbMaxPage {
+				return c.JSON(omdbError{Response: "False", Error: omdbErrInvalidArg})
+			}
+		}
+		results, total := titleIndex.Search(query, filter, (page-1)*omdbPageSize, omdbPageSize)
+		if len(results) == 0 {
+			return c.JSON(omdbError{Response: "False", Error: omdbErrNotFound})
+		}
+		ids := make([]string, len(results))
+		for i, result := range results {
+			ids[i] = result.ID
+		}
+		metas, err := getMetaList(metaStore, ids)
+		if err != nil {
+			log.Printf("Couldn't get metas: %v\n", err)
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(omdbError{Response: "False", Error: omdbErrInternal})
+		}
+		res := omdbSearch{
+			Search:       make([]omdbSearchResult, 0, len(metas)),
+			TotalResults: strconv.Itoa(total),
+			Response:     "True",
+		}
+		for _, meta := range metas {
+			if meta == nil {
+				continue
+			}
+			res.Search = append(res.Search, omdbSearchResult{
+				Title:  meta.PrimaryTitle,
+				Year:   omdbYear(meta),
+				ImdbID: meta.Id,
+				Type:   omdbType(meta.TitleType),
+				Poster: omdbNA,
+			})
+		}
+		return c.JSON(res)
+	}
+}
+
+// parseOMDbFilter parses the "y" and "type" query parameters.
+func parseOMDbFilter(c *fiber.Ctx) (index.Filter, error) {
+	var filter index.Filter
+	if yearParam := c.Query("y"); yearParam != "" {
+		year, err := strconv.Atoi(yearParam)
+		if err != nil {
+			return filter, err
+		}
+		filter.StartYearFrom = year
+		filter.StartYearTo = year
+	}
+	if omdbTypeParam := c.Query("type"); omdbTypeParam != "" {
+		for titleType := range pb.TitleType_name {
+			if omdbType(pb.TitleType(titleType)) == omdbTypeParam {
+				filter.TitleTypes = append(filter.TitleTypes, pb.TitleType(titleType))
+			}
+		}
+		if len(filter.TitleTypes) == 0 {
+			return filter, errors.New("invalid type")
+		}
+	}
+	return filter, nil
+}
+
+// sendOMDbTitle sends the title with the given ID in OMDb's format.
+func sendOMDbTitle(c *fiber.Ctx, metaStore *metaStore, id string) error {
+	metas, err := getMetaList(metaStore, []string{id})
+	if err != nil {
+		log.Printf("Couldn't get meta: %v\n", err)
+		c.Status(fiber.StatusInternalServerError)
+		return c.JSON(omdbError{Response: "False", Error: omdbErrInternal})
+	} else if metas[0] == nil {
+		return c.JSON(omdbError{Response: "False", Error: omdbErrInvalidID})
+	}
+	meta := metas[0]
+
+	res := omdbTitle{
+		Title:    meta.PrimaryTitle,
+		Year:     omdbYear(meta),
+		Runtime:  omdbNA,
+		Genre:    omdbNA,
+		ImdbID:   meta.Id,
+		Type:     omdbType(meta.TitleType),
+		Response: "True",
+	}
+	if meta.Runtime != 0 {
+		res.Runtime = strconv.Itoa(int(meta.Runtime)) + " min"
+	}
+	if len(meta.Genres) > 0 {
+		res.Genre = strings.Join(meta.Genres, ", ")
+	}
+	return c.JSON(res)
+}
+
+func omdbType(titleType pb.TitleType) string {
+	if omdbType, ok := omdbTypes[titleType]; ok {
+		return omdbType
+	}
+	return "movie"
+}
+
+// omdbYear returns the year like OMDb, which is a range like "2008–2013" (with an en dash) for series, open as long as they're running.
+func omdbYear(meta *pb.Meta) string {
+	if meta.StartYear == 0 {
+		return omdbNA
+	}
+	year := strconv.Itoa(int(meta.StartYear))
+	if omdbType(meta.TitleType) == "series" {
+		year += "–"
+		if meta.EndYear != 0 {
+			year += strconv.Itoa(int(meta.EndYear))
+		}
+	}
+	return year
+}
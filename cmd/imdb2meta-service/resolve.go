@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+	"github.com/deflix-tv/imdb2meta/release"
+)
+
+const (
+	defaultResolveLimit = 5
+	maxResolveLimit     = 20
+	// Number of title search results that are rated, per search
+	resolveCandidates = 50
+	// The year in a release name can differ from the start year on IMDb, for example when a movie premiered at a festival in the year before its release
+	yearTolerance = 1
+)
+
+// Weights of the parts of the confidence, which add up to 1.
+const (
+	titleWeight = 0.6
+	yearWeight  = 0.3
+	typeWeight  = 0.1
+)
+
+// typeFits rates how well a title type fits a release name without season/episode, which is most likely a movie.
+var typeFits = map[pb.TitleType]float64{
+	pb.TitleType_MOVIE:          1,
+	pb.TitleType_TV_MOVIE:       0.8,
+	pb.TitleType_VIDEO:          0.6,
+	pb.TitleType_TV_SPECIAL:     0.5,
+	pb.TitleType_SHORT:          0.5,
+	pb.TitleType_TV_MINI_SERIES: 0.4,
+	pb.TitleType_TV_SERIES:      0.3,
+}
+
+// resolveMatch is a candidate title for a release name.
+type resolveMatch struct {
+	id         string
+	confidence float64
+	reasons    []string
+}
+
+// resolve parses the release name, searches the title index for the title and rates the results by how well their title, year and type match.
+func resolve(metaStore *metaStore, titleIndex *index.Index, name string, limit int) (*pb.ResolveResponse, error) {
+	if limit < 0 {
+		return nil, errInvalidPagination
+	} else if limit == 0 {
+		limit = defaultResolveLimit
+	} else if limit > maxResolveLimit {
+		limit = maxResolveLimit
+	}
+
+	info := release.Parse(name)
+	res := &pb.ResolveResponse{
+		ReleaseInfo: &pb.ReleaseInfo{
+			Title:   info.Title,
+			Year:    int32(info.Year),
+			Season:  int32(info.Season),
+			Episode: int32(info.Episode),
+		},
+	}
+	if info.Title == "" {
+		return res, nil
+	}
+
+	// With a year, titles from around that year are searched separately, because they could otherwise be outranked by many titles with better matching titles
+	var results []index.Result
+	if info.Year != 0 {
+		results, _ = titleIndex.Search(info.Title, index.Filter{StartYearFrom: info.Year - yearTolerance, StartYearTo: info.Year + yearTolerance}, 0, resolveCandidates)
+	}
+	allResults, _ := titleIndex.Search(info.Title, index.Filter{}, 0, resolveCandidates)
+	results = append(results, allResults...)
+
+	seen := make(map[string]bool, len(results))
+	matches := make([]resolveMatch, 0, len(results))
+	for _, result := range results {
+		if seen[result.ID] {
+			continue
+		}
+		seen[result.ID] = true
+		matches = append(matches, rateMatch(info, result))
+	}
+	// Stable, so titles with the same confidence stay in the order of the search results
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].confidence > matches[j].confidence
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.id
+	}
+	metas, err := getMetaList(metaStore, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, match := range matches {
+		if metas[i] == nil {
+			continue
+		}
+		res.Matches = append(res.Matches, &pb.ResolveMatch{
+			Meta:       metas[i],
+			Confidence: float32(match.confidence),
+			Reasons:    match.reasons,
+		})
+	}
+	return res, nil
+}
+
+// rateMatch calculates the confidence that the search result is the title of the release, with the reasons for each part of it.
+func rateMatch(info release.Info, result index.Result) resolveMatch {
+	match := resolveMatch{id: result.ID}
+	add := func(weight, rating float64, reason string) {
+		match.confidence += weight * rating
+		match.reasons = append(match.reasons, fmt.Sprintf("%v: +%.2f", reason, weight*rating))
+	}
+
+	switch result.Match {
+	case index.MatchExact:
+		add(titleWeight, result.Score, "Title matches exactly")
+	case index.MatchPrefix:
+		add(titleWeight, result.Score, "Title starts with the parsed title")
+	default:
+		add(titleWeight, result.Score, "Title contains all words of the parsed title")
+	}
+
+	switch diff := abs(info.Year - result.StartYear); {
+	case info.Year == 0:
+		add(yearWeight, 0.5, "No year in the name")
+	case result.StartYear == 0:
+		add(yearWeight, 0.3, "Title has no year")
+	case diff == 0:
+		add(yearWeight, 1, "Year matches")
+	case diff <= yearTolerance:
+		add(yearWeight, 0.6, fmt.Sprintf("Year differs by %v", diff))
+	default:
+		add(yearWeight, 0, fmt.Sprintf("Year differs by %v", diff))
+	}
+
+	if info.IsEpisodic() {
+		if result.TitleType == pb.TitleType_TV_SERIES || result.TitleType == pb.TitleType_TV_MINI_SERIES {
+			add(typeWeight, 1, "TV series matches the season/episode in the name")
+		} else {
+			add(typeWeight, 0, "Not a TV series, but the name contains a season/episode")
+		}
+	} else {
+		add(typeWeight, typeFits[result.TitleType], fmt.Sprintf("Type %v for a name without season/episode", result.TitleType))
+	}
+
+	return match
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
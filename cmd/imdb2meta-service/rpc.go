@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// rpc is an RPC of the gRPC server that can be called directly with a request message, for serving it via other protocols than gRPC.
+type rpc struct {
+	desc    protoreflect.MethodDescriptor
+	reqType protoreflect.MessageType
+	srv     interface{}
+	// Only one of them is set, depending on whether the RPC is unary or server streaming
+	unaryHandler  func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error)
+	streamHandler grpc.StreamHandler
+}
+
+// serviceRPCs returns the unary and server streaming RPCs of the service, implemented by srv.
+// Client streaming RPCs are left out, because the protocols that are based on HTTP/1.1 can't stream requests.
+func serviceRPCs(serviceDesc *grpc.ServiceDesc, srv interface{}) ([]*rpc, error) {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceDesc.ServiceName))
+	if err != nil {
+		return nil, err
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%v isn't a service", serviceDesc.ServiceName)
+	}
+
+	var rpcs []*rpc
+	methods := sd.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		md := methods.Get(i)
+		if md.IsStreamingClient() {
+			continue
+		}
+		reqType, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+		if err != nil {
+			return nil, err
+		}
+		r := &rpc{desc: md, reqType: reqType, srv: srv}
+		if md.IsStreamingServer() {
+			for _, streamDesc := range serviceDesc.Streams {
+				if streamDesc.StreamName == string(md.Name()) {
+					r.streamHandler = streamDesc.Handler
+				}
+			}
+		} else {
+			for _, methodDesc := range serviceDesc.Methods {
+				if methodDesc.MethodName == string(md.Name()) {
+					r.unaryHandler = methodDesc.Handler
+				}
+			}
+		}
+		if r.unaryHandler == nil && r.streamHandler == nil {
+			return nil, fmt.Errorf("RPC %v isn't implemented by the service", md.Name())
+		}
+		rpcs = append(rpcs, r)
+	}
+	return rpcs, nil
+}
+
+// callUnary calls a unary RPC.
+func (r *rpc) callUnary(ctx context.Context, req proto.Message) (proto.Message, error) {
+	res, err := r.unaryHandler(r.srv, ctx, func(in interface{}) error {
+		proto.Merge(in.(proto.Message), req)
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.(proto.Message), nil
+}
+
+// callStream calls a server streaming RPC, with send being called for each response message.
+// When send returns an error, the RPC returns it as well.
+func (r *rpc) callStream(ctx context.Context, req proto.Message, send func(proto.Message) error) error {
+	return r.streamHandler(r.srv, &rpcServerStream{ctx: ctx, req: req, send: send})
+}
+
+// rpcServerStream is a grpc.ServerStream that receives a single request message and hands the sent messages over to a function.
+type rpcServerStream struct {
+	ctx  context.Context
+	req  proto.Message
+	send func(proto.Message) error
+}
+
+func (s *rpcServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *rpcServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *rpcServerStream) SetTrailer(metadata.MD)       {}
+func (s *rpcServerStream) Context() context.Context     { return s.ctx }
+func (s *rpcServerStream) SendMsg(m interface{}) error  { return s.send(m.(proto.Message)) }
+
+func (s *rpcServerStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+var (
+	errInvalidPagination = errors.New("offset and limit must not be negative")
+	errInvalidTitleType  = errors.New("unknown title type")
+)
+
+// searchLimit returns the limit to use for a requested limit, which can be 0 for the default.
+func searchLimit(limit int) int {
+	if limit == 0 {
+		return defaultSearchLimit
+	} else if limit > maxSearchLimit {
+		return maxSearchLimit
+	}
+	return limit
+}
+
+// search searches the title index and fetches the metas of the results.
+// Results whose meta isn't in the DB (for example when the index was built from a different TSV file than the DB) are skipped.
+func search(metaStore *metaStore, titleIndex *index.Index, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, errInvalidPagination
+	}
+	filter, err := toIndexFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var results []index.Result
+	var total int
+	if req.Fuzzy {
+		results, total = titleIndex.FuzzySearch(req.Query, filter, int(req.Offset), searchLimit(int(req.Limit)))
+	} else {
+		results, total = titleIndex.Search(req.Query, filter, int(req.Offset), searchLimit(int(req.Limit)))
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	metas, err := getMetaList(metaStore, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.SearchResponse{
+		Results: make([]*pb.SearchResult, 0, len(results)),
+		Total:   int32(total),
+	}
+	for i, result := range results {
+		if metas[i] == nil {
+			continue
+		}
+		res.Results = append(res.Results, &pb.SearchResult{
+			Meta:  metas[i],
+			Score: float32(result.Score),
+		})
+	}
+	return res, nil
+}
+
+// toIndexFilter converts the filter of a request to a filter for the title index. The filter can be nil.
+// It returns errInvalidTitleType for title types that don't exist, which requests can contain as any number.
+func toIndexFilter(filter *pb.MetaFilter) (index.Filter, error) {
+	if filter == nil {
+		return index.Filter{}, nil
+	}
+	for _, titleType := range filter.GetTitleTypes() {
+		if _, ok := pb.TitleType_name[int32(titleType)]; !ok {
+			return index.Filter{}, errInvalidTitleType
+		}
+	}
+	return index.Filter{
+		TitleTypes:    filter.GetTitleTypes(),
+		StartYearFrom: int(filter.GetStartYearFrom()),
+		StartYearTo:   int(filter.GetStartYearTo()),
+		Genre:         filter.GetGenre(),
+		MinRuntime:    int(filter.GetMinRuntime()),
+		Adult:         filter.Adult,
+	}, nil
+}
@@ -1,22 +1,287 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/dgraph-io/badger/v2"
+	"github.com/klauspost/compress/zstd"
 	"go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/pb"
+	"github.com/deflix-tv/imdb2meta/staticdb"
 )
 
 type metaStore struct {
 	badgerDB *badger.DB
 	boltDB   *bbolt.DB
+	staticDB *staticdb.DB
+	memStore *memStore
+
+	keyEncoding imdbid.KeyEncoding
+	// Only set if the values are compressed
+	decoder *zstd.Decoder
 }
 
 func (s *metaStore) Get(id string) ([]byte, error) {
+	metaBytes, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.decompress(metaBytes)
+}
+
+// GetMany gets the values for multiple IDs, reading all of them in a single DB transaction.
+// The returned slice has the same order as the IDs, with nil for IDs that weren't found.
+func (s *metaStore) GetMany(ids []string) ([][]byte, error) {
+	keys := make([][]byte, len(ids))
+	for i, id := range ids {
+		// IDs that can't be in the DB keep the nil key
+		if key, ok := s.dbKey(make([]byte, 0, len(id)), id); ok {
+			keys[i] = key
+		}
+	}
+
+	metas := make([][]byte, len(ids))
 	var err error
-	var metaBytes []byte
+	if s.memStore != nil {
+		for i, key := range keys {
+			if key != nil {
+				metas[i], _ = s.memStore.Get(string(key))
+			}
+		}
+	} else if s.staticDB != nil {
+		for i, key := range keys {
+			if key != nil {
+				metas[i], _ = s.staticDB.Get(string(key))
+			}
+		}
+	} else if s.badgerDB != nil {
+		err = s.badgerDB.View(func(txn *badger.Txn) error {
+			for i, key := range keys {
+				if key == nil {
+					continue
+				}
+				item, err := txn.Get(key)
+				if err == badger.ErrKeyNotFound {
+					continue
+				} else if err != nil {
+					return err
+				}
+				if metas[i], err = item.ValueCopy(nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	} else {
+		err = s.boltDB.View(func(tx *bbolt.Tx) error {
+			bucket := tx.Bucket(imdbBytes)
+			for i, key := range keys {
+				if key == nil {
+					continue
+				}
+				if txBytes := bucket.Get(key); txBytes != nil {
+					// The value is only valid during the transaction
+					metas[i] = append([]byte(nil), txBytes...)
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, metaBytes := range metas {
+		if metaBytes == nil {
+			continue
+		}
+		if metas[i], err = s.decompress(metaBytes); err != nil {
+			return nil, err
+		}
+	}
+	return metas, nil
+}
+
+// forEachChunkSize is the number of values that ForEach reads from BadgerDB and bbolt per transaction.
+const forEachChunkSize = 1000
+
+// ForEach calls fn for every value in the DB, in the order of the keys.
+// BadgerDB and bbolt are read in chunks, each in its own short transaction, and fn is called after the transaction,
+// so that a slow fn (for example sending to a slow streaming client) doesn't keep a transaction open for a long time.
+// The value must not be modified and is only valid during the call.
+func (s *metaStore) ForEach(fn func(metaBytes []byte) error) error {
+	decompressFn := func(metaBytes []byte) error {
+		metaBytes, err := s.decompress(metaBytes)
+		if err != nil {
+			return err
+		}
+		return fn(metaBytes)
+	}
+
+	if s.memStore != nil {
+		for i := 0; i < s.memStore.Len(); i++ {
+			if err := decompressFn(s.memStore.values[s.memStore.valOffsets[i]:s.memStore.valOffsets[i+1]]); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if s.staticDB != nil {
+		// No transactions, so the memory-mapped file can be read directly
+		return s.staticDB.ForEach(func(_, value []byte) error {
+			return decompressFn(value)
+		})
+	}
+
+	var nextKey []byte
+	for {
+		values, chunkNextKey, err := s.readChunk(nextKey)
+		if err != nil {
+			return err
+		}
+		for _, value := range values {
+			if err = decompressFn(value); err != nil {
+				return err
+			}
+		}
+		if chunkNextKey == nil {
+			return nil
+		}
+		nextKey = chunkNextKey
+	}
+}
 
+// readChunk reads up to forEachChunkSize values from BadgerDB or bbolt, starting at the given key or at the first key if it's nil.
+// The values are copied, because they're used after the transaction. The returned key is the one to continue with, or nil if there are no more values.
+func (s *metaStore) readChunk(from []byte) ([][]byte, []byte, error) {
+	var values [][]byte
+	var nextKey []byte
+	var err error
 	if s.badgerDB != nil {
 		err = s.badgerDB.View(func(txn *badger.Txn) error {
-			item, err := txn.Get([]byte(id))
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			defer it.Close()
+			for it.Seek(from); it.Valid(); it.Next() {
+				item := it.Item()
+				if len(values) == forEachChunkSize {
+					nextKey = item.KeyCopy(nil)
+					return nil
+				}
+				if hasMetadataPrefix(item.Key()) {
+					continue
+				}
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				values = append(values, value)
+			}
+			return nil
+		})
+	} else {
+		err = s.boltDB.View(func(tx *bbolt.Tx) error {
+			c := tx.Bucket(imdbBytes).Cursor()
+			for key, value := c.Seek(from); key != nil; key, value = c.Next() {
+				if len(values) == forEachChunkSize {
+					nextKey = append([]byte(nil), key...)
+					return nil
+				}
+				// The value is only valid during the transaction
+				values = append(values, append([]byte(nil), value...))
+			}
+			return nil
+		})
+	}
+	return values, nextKey, err
+}
+
+// getMetas gets the Meta objects for multiple IDs, for the HTTP and gRPC batch endpoints.
+func getMetas(metaStore *metaStore, ids []string) (*pb.MetasResponse, error) {
+	metas, err := getMetaList(metaStore, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.MetasResponse{
+		Metas: make([]*pb.Meta, 0, len(ids)),
+	}
+	for i, meta := range metas {
+		if meta == nil {
+			res.MissingIds = append(res.MissingIds, ids[i])
+			continue
+		}
+		res.Metas = append(res.Metas, meta)
+	}
+	return res, nil
+}
+
+// getMetaList returns the metas for the IDs in the same order, with nil for IDs that aren't found.
+func getMetaList(metaStore *metaStore, ids []string) ([]*pb.Meta, error) {
+	metasBytes, err := metaStore.GetMany(ids)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data from DB: %w", err)
+	}
+	metas := make([]*pb.Meta, len(ids))
+	for i, metaBytes := range metasBytes {
+		if metaBytes == nil {
+			continue
+		}
+		meta := &pb.Meta{}
+		if err = proto.Unmarshal(metaBytes, meta); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal protocol buffer into object: %w", err)
+		}
+		metas[i] = meta
+	}
+	return metas, nil
+}
+
+// decompress decompresses the value if the values in the DB are compressed, otherwise it returns the value as is.
+func (s *metaStore) decompress(metaBytes []byte) ([]byte, error) {
+	if s.decoder == nil {
+		return metaBytes, nil
+	}
+	// The importer doesn't store the zstd magic number, because it's the same for all values
+	frame := make([]byte, 0, len(zstdMagic)+len(metaBytes))
+	frame = append(frame, zstdMagic...)
+	frame = append(frame, metaBytes...)
+	return s.decoder.DecodeAll(frame, nil)
+}
+
+// dbKey appends the DB key of the ID to buf. It returns false for IDs that can't be in the DB, which are all IDs that aren't valid IMDb IDs.
+// With the string encoding the key is the ID itself, so without the check IDs like "metadata:zstdDict" would read the importer's metadata from BadgerDB,
+// and empty IDs would fail the BadgerDB transaction instead of not being found.
+func (s *metaStore) dbKey(buf []byte, id string) ([]byte, bool) {
+	if _, _, err := imdbid.Parse(id); err != nil {
+		return nil, false
+	}
+	key, err := s.keyEncoding.AppendKey(buf, id)
+	return key, err == nil
+}
+
+func (s *metaStore) get(id string) ([]byte, error) {
+	var err error
+	var metaBytes []byte
+
+	// Enough for the IDs as string as well as numeric key, so it doesn't need to be allocated on the heap
+	var keyBuf [16]byte
+	key, ok := s.dbKey(keyBuf[:0], id)
+	if !ok {
+		return nil, errNotFound
+	}
+
+	if s.memStore != nil {
+		return s.memStore.Get(string(key))
+	} else if s.staticDB != nil {
+		// Zero-copy lookup in the memory-mapped file
+		metaBytes, found := s.staticDB.Get(string(key))
+		if !found {
+			return nil, errNotFound
+		}
+		return metaBytes, nil
+	} else if s.badgerDB != nil {
+		err = s.badgerDB.View(func(txn *badger.Txn) error {
+			item, err := txn.Get(key)
 			if err != nil {
 				if err == badger.ErrKeyNotFound {
 					return errNotFound
@@ -28,11 +293,12 @@ func (s *metaStore) Get(id string) ([]byte, error) {
 		})
 	} else {
 		err = s.boltDB.View(func(tx *bbolt.Tx) error {
-			txBytes := tx.Bucket(imdbBytes).Get([]byte(id))
+			txBytes := tx.Bucket(imdbBytes).Get(key)
 			if txBytes == nil {
 				return errNotFound
 			}
-			copy(metaBytes, txBytes)
+			// The value is only valid during the transaction
+			metaBytes = append([]byte(nil), txBytes...)
 			return nil
 		})
 	}
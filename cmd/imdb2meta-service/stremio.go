@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// Stremio addon protocol, see https://github.com/Stremio/stremio-addon-sdk/tree/master/docs
+
+const (
+	stremioMovieCatalogID  = "imdb2meta-movies"
+	stremioSeriesCatalogID = "imdb2meta-series"
+	// Stremio requests the next page of a catalog with a "skip" of the number of already received metas, and expects pages of 100 metas.
+	stremioPageSize = 100
+	// Maximum number of remembered catalog cursors, which is the number of pages that can be continued without listing the skipped titles again
+	stremioCursorCacheSize = 10000
+	// Maximum skip for listing without a remembered cursor, because then the skipped titles must be listed as well
+	stremioMaxUncachedSkip = 10000
+)
+
+// stremioTitleTypes are the title types that are served as Stremio "movie" and "series" metas.
+// Other title types like episodes and video games don't exist in Stremio.
+var stremioTitleTypes = map[pb.TitleType]string{
+	pb.TitleType_MOVIE:          "movie",
+	pb.TitleType_TV_MOVIE:       "movie",
+	pb.TitleType_SHORT:          "movie",
+	pb.TitleType_TV_SHORT:       "movie",
+	pb.TitleType_VIDEO:          "movie",
+	pb.TitleType_TV_SPECIAL:     "movie",
+	pb.TitleType_TV_SERIES:      "series",
+	pb.TitleType_TV_MINI_SERIES: "series",
+}
+
+// stremioCatalogTitleTypes are the title types that the catalogs contain, which are fewer than the ones of stremioTitleTypes,
+// so the catalogs aren't cluttered with shorts and videos.
+var stremioCatalogTitleTypes = map[string][]pb.TitleType{
+	"movie":  {pb.TitleType_MOVIE, pb.TitleType_TV_MOVIE},
+	"series": {pb.TitleType_TV_SERIES, pb.TitleType_TV_MINI_SERIES},
+}
+
+type stremioManifest struct {
+	ID          string           `json:"id"`
+	Version     string           `json:"version"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Resources   []string         `json:"resources"`
+	Types       []string         `json:"types"`
+	IDprefixes  []string         `json:"idPrefixes"`
+	Catalogs    []stremioCatalog `json:"catalogs"`
+}
+
+type stremioCatalog struct {
+	Type  string         `json:"type"`
+	ID    string         `json:"id"`
+	Name  string         `json:"name"`
+	Extra []stremioExtra `json:"extra,omitempty"`
+}
+
+type stremioExtra struct {
+	Name    string   `json:"name"`
+	Options []string `json:"options,omitempty"`
+}
+
+type stremioMeta struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Genres      []string `json:"genres,omitempty"`
+	ReleaseInfo string   `json:"releaseInfo,omitempty"`
+	Runtime     string   `json:"runtime,omitempty"`
+}
+
+// createStremioManifest creates the manifest of the addon.
+// Without title index, the addon only provides metas, no catalogs.
+func createStremioManifest(titleIndex *index.Index) stremioManifest {
+	manifest := stremioManifest{
+		ID:          "tv.deflix.imdb2meta",
+		Version:     "0.1.0",
+		Name:        "imdb2meta",
+		Description: "Metadata of movies and TV shows from the IMDb datasets",
+		Resources:   []string{"meta"},
+		Types:       []string{"movie", "series"},
+		IDprefixes:  []string{"tt"},
+		Catalogs:    []stremioCatalog{},
+	}
+	if titleIndex != nil {
+		manifest.Resources = append(manifest.Resources, "catalog")
+		extra := []stremioExtra{
+			{Name: "search"},
+			{Name: "genre", Options: titleIndex.GenreNames()},
+			{Name: "skip"},
+		}
+		manifest.Catalogs = []stremioCatalog{
+			{Type: "movie", ID: stremioMovieCatalogID, Name: "IMDb movies", Extra: extra},
+			{Type: "series", ID: stremioSeriesCatalogID, Name: "IMDb series", Extra: extra},
+		}
+	}
+	return manifest
+}
+
+// createStremioManifestHandler creates a handler that responds with the addon manifest.
+func createStremioManifestHandler(titleIndex *index.Index) (fiber.Handler, error) {
+	manifestJSON, err := json.Marshal(createStremioManifest(titleIndex))
+	if err != nil {
+		return nil, err
+	}
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(manifestJSON)
+	}, nil
+}
+
+// createStremioMetaHandler creates a handler for the addon's meta resource, like "/meta/movie/tt1254207.json".
+func createStremioMetaHandler(metaStore *metaStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stremioType := c.Params("type")
+		id := strings.TrimSuffix(c.Params("id"), ".json")
+
+		metaBytes, err := metaStore.Get(id)
+		if err != nil {
+			if err == errNotFound {
+				log.Printf("Key not found in DB: %v\n", err)
+				return c.SendStatus(fiber.StatusNotFound)
+			}
+			log.Printf("Couldn't get data from DB: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		meta := &pb.Meta{}
+		if err = proto.Unmarshal(metaBytes, meta); err != nil {
+			log.Printf("Couldn't unmarshal protocol buffer into object: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		// The title must have the requested type
+		if stremioTitleTypes[meta.TitleType] != stremioType {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+
+		return c.JSON(map[string]stremioMeta{"meta": toStremioMeta(meta)})
+	}
+}
+
+// createStremioCatalogHandler creates a handler for the addon's catalog resource, like "/catalog/movie/imdb2meta-movies.json"
+// or with extra properties "/catalog/movie/imdb2meta-movies/genre=Horror&skip=100.json".
+// With the "search" extra property it searches the titles, otherwise it lists them in the order of their IDs.
+func createStremioCatalogHandler(metaStore *metaStore, titleIndex *index.Index) fiber.Handler {
+	cursors := newStremioCursorCache(stremioCursorCacheSize)
+	return func(c *fiber.Ctx) error {
+		stremioType := c.Params("type")
+		catalogID := strings.TrimSuffix(c.Params("id"), ".json")
+		if (stremioType != "movie" || catalogID != stremioMovieCatalogID) && (stremioType != "series" || catalogID != stremioSeriesCatalogID) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		extra, err := url.ParseQuery(strings.TrimSuffix(c.Params("extra"), ".json"))
+		if err != nil {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		skip := 0
+		if skipParam := extra.Get("skip"); skipParam != "" {
+			if skip, err = strconv.Atoi(skipParam); err != nil || skip < 0 {
+				return c.SendStatus(fiber.StatusBadRequest)
+			}
+		}
+
+		filter := index.Filter{
+			TitleTypes: stremioCatalogTitleTypes[stremioType],
+			Genre:      extra.Get("genre"),
+		}
+		var ids []string
+		// Only set when listing, for remembering the cursor of the next page
+		var cursorKey *stremioCursorKey
+		if query := extra.Get("search"); query != "" {
+			results, _ := titleIndex.Search(query, filter, skip, stremioPageSize)
+			for _, result := range results {
+				ids = append(ids, result.ID)
+			}
+		} else {
+			// Listing only supports cursors, so the cursor after the last meta of each page is remembered for the request of the next page
+			key := stremioCursorKey{stremioType: stremioType, genre: strings.ToLower(filter.Genre), skip: skip}
+			after, ok := uint32(0), skip == 0
+			if !ok {
+				after, ok = cursors.Get(key)
+			}
+			if ok {
+				ids, _ = titleIndex.List(filter, after, stremioPageSize)
+			} else if skip <= stremioMaxUncachedSkip {
+				// Without the cursor, for example after a restart, the skipped titles must be listed as well
+				ids, _ = titleIndex.List(filter, 0, skip+stremioPageSize)
+				if skip < len(ids) {
+					ids = ids[skip:]
+				} else {
+					ids = nil
+				}
+			}
+			// Larger skips without cursor get an empty page, which ends the catalog in Stremio
+			cursorKey = &key
+		}
+
+		metas, err := getMetaList(metaStore, ids)
+		if err != nil {
+			log.Printf("Couldn't get metas: %v\n", err)
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		stremioMetas := make([]stremioMeta, 0, len(metas))
+		for _, meta := range metas {
+			if meta != nil {
+				stremioMetas = append(stremioMetas, toStremioMeta(meta))
+			}
+		}
+		if cursorKey != nil && len(ids) > 0 {
+			// The skip of the next page is the number of received metas, without the IDs whose meta isn't in the DB.
+			// The IDs from the index are always valid.
+			_, number, _ := imdbid.Parse(ids[len(ids)-1])
+			cursorKey.skip += len(stremioMetas)
+			cursors.Add(*cursorKey, number)
+		}
+		return c.JSON(map[string][]stremioMeta{"metas": stremioMetas})
+	}
+}
+
+// toStremioMeta converts a Meta to Stremio's meta object.
+func toStremioMeta(meta *pb.Meta) stremioMeta {
+	stremioMeta := stremioMeta{
+		ID:     meta.Id,
+		Type:   stremioTitleTypes[meta.TitleType],
+		Name:   meta.PrimaryTitle,
+		Genres: meta.Genres,
+	}
+	if meta.StartYear != 0 {
+		stremioMeta.ReleaseInfo = strconv.Itoa(int(meta.StartYear))
+		// Series have a year range, which is open as long as they're running
+		if stremioMeta.Type == "series" {
+			stremioMeta.ReleaseInfo += "-"
+			if meta.EndYear != 0 {
+				stremioMeta.ReleaseInfo += strconv.Itoa(int(meta.EndYear))
+			}
+		}
+	}
+	if meta.Runtime != 0 {
+		stremioMeta.Runtime = strconv.Itoa(int(meta.Runtime)) + " min"
+	}
+	return stremioMeta
+}
+
+// stremioCursorKey identifies a page of a catalog by the Stremio type, the lowercase genre and the number of skipped metas.
+type stremioCursorKey struct {
+	stremioType string
+	genre       string
+	skip        int
+}
+
+// stremioCursorCache remembers the numeric ID of the last title before a catalog page, so the page can be listed with a cursor.
+// When it's full, the oldest cursor is evicted. It's safe for concurrent use.
+type stremioCursorCache struct {
+	maxEntries int
+	lock       sync.Mutex
+	cursors    map[stremioCursorKey]uint32
+	// Keys in the order they were added, as ring buffer with the oldest key at next when it's full
+	keys []stremioCursorKey
+	next int
+}
+
+func newStremioCursorCache(maxEntries int) *stremioCursorCache {
+	return &stremioCursorCache{
+		maxEntries: maxEntries,
+		cursors:    make(map[stremioCursorKey]uint32, maxEntries),
+		keys:       make([]stremioCursorKey, 0, maxEntries),
+	}
+}
+
+func (c *stremioCursorCache) Get(key stremioCursorKey) (uint32, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	after, ok := c.cursors[key]
+	return after, ok
+}
+
+func (c *stremioCursorCache) Add(key stremioCursorKey, after uint32) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.cursors[key]; ok {
+		c.cursors[key] = after
+		return
+	}
+	if len(c.keys) < c.maxEntries {
+		c.keys = append(c.keys, key)
+	} else {
+		delete(c.cursors, c.keys[c.next])
+		c.keys[c.next] = key
+		c.next = (c.next + 1) % c.maxEntries
+	}
+	c.cursors[key] = after
+}
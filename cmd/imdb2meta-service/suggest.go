@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+const defaultSuggestLimit = 10
+
+// suggest gets the most popular titles that start with the prefix from the title index and fetches their metas.
+func suggest(metaStore *metaStore, titleIndex *index.Index, prefix string, limit int) (*pb.SuggestResponse, error) {
+	if limit < 0 {
+		return nil, errInvalidPagination
+	} else if limit == 0 {
+		limit = defaultSuggestLimit
+	}
+
+	ids := titleIndex.Suggest(prefix, limit)
+	metas, err := getMetaList(metaStore, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.SuggestResponse{
+		Metas: make([]*pb.Meta, 0, len(metas)),
+	}
+	for _, meta := range metas {
+		if meta != nil {
+			res.Metas = append(res.Metas, meta)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/index"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// cursorVersion is the first byte of cursors, so the cursor format can be changed without misinterpreting old cursors.
+const cursorVersion = 1
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// listTitles lists the titles that match the filter, starting after the title of the cursor, and fetches their metas.
+func listTitles(metaStore *metaStore, titleIndex *index.Index, req *pb.ListTitlesRequest) (*pb.ListTitlesResponse, error) {
+	if req.Limit < 0 {
+		return nil, errInvalidPagination
+	}
+	var after uint32
+	if req.Cursor != "" {
+		var err error
+		if after, err = decodeCursor(req.Cursor); err != nil {
+			return nil, errInvalidCursor
+		}
+	}
+
+	filter, err := toIndexFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	ids, more := titleIndex.List(filter, after, searchLimit(int(req.Limit)))
+	metas, err := getMetaList(metaStore, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ListTitlesResponse{
+		Metas: make([]*pb.Meta, 0, len(metas)),
+	}
+	for _, meta := range metas {
+		if meta != nil {
+			res.Metas = append(res.Metas, meta)
+		}
+	}
+	if more {
+		// The IDs from the index are always valid
+		_, number, _ := imdbid.Parse(ids[len(ids)-1])
+		res.NextCursor = encodeCursor(number)
+	}
+	return res, nil
+}
+
+// encodeCursor creates an opaque cursor that points after the title with the numeric ID.
+// As the titles are listed in the order of their IDs, the cursor stays valid when the index is rebuilt.
+func encodeCursor(after uint32) string {
+	var b [5]byte
+	b[0] = cursorVersion
+	binary.BigEndian.PutUint32(b[1:], after)
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+func decodeCursor(cursor string) (uint32, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	} else if len(b) != 5 || b[0] != cursorVersion {
+		return 0, errInvalidCursor
+	}
+	return binary.BigEndian.Uint32(b[1:]), nil
+}
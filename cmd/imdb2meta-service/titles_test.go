@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	for _, after := range []uint32{0, 1, 68646, 10000000, math.MaxUint32} {
+		cursor := encodeCursor(after)
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			t.Fatalf("couldn't decode cursor %q: %v", cursor, err)
+		}
+		if decoded != after {
+			t.Errorf("expected %v after cursor round-trip, got %v", after, decoded)
+		}
+	}
+
+	for _, cursor := range []string{
+		"",
+		"not base64!",
+		"AQAAAAE=",   // Padding isn't allowed
+		"AQAAAA",     // Too short
+		"AQAAAAEA",   // Too long
+		"AgAAAAE",    // Unknown version
+		"dHQwMDY4Ng", // "tt00686"
+	} {
+		if after, err := decodeCursor(cursor); err == nil {
+			t.Errorf("expected an error for cursor %q, got %v", cursor, after)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// REST transcoding of the gRPC service, like with gRPC-Gateway, see https://cloud.google.com/endpoints/docs/grpc/transcoding
+//
+// Each RPC with a google.api.http annotation in service.proto gets an HTTP route. Its handler converts the HTTP request to the request message,
+// calls the gRPC server's implementation of the RPC directly and sends the response message in the format that the client accepts.
+// Path templates only support simple variables like "/v1/metas/{id}", without verbs.
+
+// registerTranscodedRoutes registers the HTTP routes for the google.api.http annotations of the service's RPCs,
+// with the handlers being called before the transcoding handler, for example middlewares.
+func registerTranscodedRoutes(router fiber.Router, serviceDesc *grpc.ServiceDesc, srv interface{}, handlers ...fiber.Handler) error {
+	rpcs, err := serviceRPCs(serviceDesc, srv)
+	if err != nil {
+		return err
+	}
+	for _, r := range rpcs {
+		rule, _ := proto.GetExtension(r.desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if rule == nil {
+			continue
+		}
+		for _, binding := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+			method, path, err := httpRulePattern(binding)
+			if err != nil {
+				return fmt.Errorf("invalid HTTP rule of RPC %v: %w", r.desc.Name(), err)
+			}
+			route, err := newTranscodedRoute(r.desc.Input(), path, binding.Body, binding.ResponseBody)
+			if err != nil {
+				return fmt.Errorf("invalid HTTP rule of RPC %v: %w", r.desc.Name(), err)
+			}
+			router.Add(method, route.path, append(handlers, createTranscodingHandler(route, r))...)
+		}
+	}
+	return nil
+}
+
+// legacyRoute is an HTTP route from before the REST API, which is served by the transcoding handler of an RPC as well.
+type legacyRoute struct {
+	method string
+	path   string
+	rpc    string
+	// Like the body of an HTTP rule
+	body string
+	// Field paths of the accepted query parameters, by their names
+	params map[string]string
+}
+
+// registerLegacyRoutes registers the legacy routes with the transcoding handlers of their RPCs,
+// with the handlers being called before the transcoding handler, for example middlewares.
+func registerLegacyRoutes(router fiber.Router, serviceDesc *grpc.ServiceDesc, srv interface{}, routes []legacyRoute, handlers ...fiber.Handler) error {
+	rpcs, err := serviceRPCs(serviceDesc, srv)
+	if err != nil {
+		return err
+	}
+	for _, legacy := range routes {
+		var legacyRPC *rpc
+		for _, r := range rpcs {
+			if string(r.desc.Name()) == legacy.rpc {
+				legacyRPC = r
+			}
+		}
+		if legacyRPC == nil {
+			return fmt.Errorf("RPC %v of legacy route %v doesn't exist", legacy.rpc, legacy.path)
+		}
+		route, err := newTranscodedRoute(legacyRPC.desc.Input(), legacy.path, legacy.body, "")
+		if err != nil {
+			return fmt.Errorf("invalid legacy route %v: %w", legacy.path, err)
+		}
+		route.queryAliases = legacy.params
+		router.Add(legacy.method, route.path, append(handlers, createTranscodingHandler(route, legacyRPC))...)
+	}
+	return nil
+}
+
+func httpRulePattern(rule *annotations.HttpRule) (string, string, error) {
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		return fiber.MethodGet, pattern.Get, nil
+	case *annotations.HttpRule_Put:
+		return fiber.MethodPut, pattern.Put, nil
+	case *annotations.HttpRule_Post:
+		return fiber.MethodPost, pattern.Post, nil
+	case *annotations.HttpRule_Delete:
+		return fiber.MethodDelete, pattern.Delete, nil
+	case *annotations.HttpRule_Patch:
+		return fiber.MethodPatch, pattern.Patch, nil
+	case *annotations.HttpRule_Custom:
+		return pattern.Custom.GetKind(), pattern.Custom.GetPath(), nil
+	default:
+		return "", "", errors.New("missing pattern")
+	}
+}
+
+// transcodedRoute is the Fiber route of an HTTP rule, with the fields of the request message that the path variables and body refer to.
+type transcodedRoute struct {
+	path string
+	// Field paths of the path variables, with the route parameter "v<i>" for the i-th variable
+	pathFields [][]protoreflect.FieldDescriptor
+	// Fields that are set via the path, which can't be set via query parameters
+	pathFieldSet map[protoreflect.FieldDescriptor]bool
+	// "*" for the whole request message
+	body      string
+	bodyField protoreflect.FieldDescriptor
+	// For legacy routes the field paths of the accepted query parameters, by their names.
+	// Other query parameters are ignored, as are empty values, and repeated fields take comma-separated values.
+	queryAliases map[string]string
+}
+
+func newTranscodedRoute(input protoreflect.MessageDescriptor, template, body, responseBody string) (*transcodedRoute, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("unsupported path template %q", template)
+	}
+	route := &transcodedRoute{
+		pathFieldSet: make(map[protoreflect.FieldDescriptor]bool),
+		body:         body,
+	}
+	segments := strings.Split(template[1:], "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") {
+			if strings.ContainsAny(segment, "{}:*") {
+				return nil, fmt.Errorf("unsupported path template %q", template)
+			}
+			continue
+		}
+		variable := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}"), "=*")
+		if strings.ContainsAny(variable, "{}=*:") {
+			return nil, fmt.Errorf("unsupported path template %q", template)
+		}
+		fields, err := fieldPath(input, variable)
+		if err != nil {
+			return nil, err
+		} else if last := fields[len(fields)-1]; last.IsList() || last.Message() != nil {
+			return nil, fmt.Errorf("path variable %q must be a scalar field", variable)
+		}
+		segments[i] = ":v" + strconv.Itoa(len(route.pathFields))
+		route.pathFields = append(route.pathFields, fields)
+		route.pathFieldSet[fields[len(fields)-1]] = true
+	}
+	route.path = "/" + strings.Join(segments, "/")
+
+	if body != "" && body != "*" {
+		fields, err := fieldPath(input, body)
+		if err != nil {
+			return nil, err
+		} else if len(fields) != 1 || fields[0].IsMap() || (fields[0].Message() == nil && !fields[0].IsList()) {
+			return nil, fmt.Errorf("body %q must be a message or repeated field of the request", body)
+		}
+		route.bodyField = fields[0]
+	}
+	if responseBody != "" {
+		return nil, fmt.Errorf("response body %q isn't supported", responseBody)
+	}
+	return route, nil
+}
+
+// fieldPath looks up the fields of a dot-separated path like "filter.title_types". The fields can have their name from the .proto file or their JSON name.
+func fieldPath(md protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	var fields []protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if md == nil {
+			return nil, fmt.Errorf("field %q of %q isn't a message field", fields[i-1].Name(), path)
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			for j := 0; j < md.Fields().Len(); j++ {
+				if md.Fields().Get(j).JSONName() == name {
+					fd = md.Fields().Get(j)
+					break
+				}
+			}
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("field %q doesn't exist", path)
+		}
+		fields = append(fields, fd)
+		md = fd.Message()
+	}
+	return fields, nil
+}
+
+// sendStream calls a server streaming RPC and sends its messages as newline-delimited JSON.
+// The HTTP status is sent before the first message, so errors are sent as last line, like {"error":{"code":13,"message":"Couldn't get metas"}}.
+func sendStream(c *fiber.Ctx, r *rpc, req proto.Message) error {
+	opts := marshalOptions(c)
+	// Each message must be on one line
+	opts.Multiline = false
+	opts.Indent = ""
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var writeErr error
+		err := r.callStream(ctx, req, func(m proto.Message) error {
+			msgJSON, err := opts.Marshal(m)
+			if err != nil {
+				return err
+			}
+			if _, writeErr = w.Write(append(msgJSON, '\n')); writeErr == nil {
+				// Flushing fails when the client is gone, which stops the RPC
+				writeErr = w.Flush()
+			}
+			if writeErr != nil {
+				return status.Error(codes.Canceled, "Couldn't write response")
+			}
+			return nil
+		})
+		if err != nil && writeErr == nil {
+			statusJSON, _ := protojson.Marshal(status.Convert(err).Proto())
+			_, _ = w.WriteString(`{"error":` + string(statusJSON) + "}\n")
+		}
+	})
+	return nil
+}
+
+// createTranscodingHandler creates a handler that converts the HTTP request to the request message of the route's RPC and calls it.
+// The request message is populated from the body, then the path variables, then the query parameters, which can set any fields that aren't set by the path or the body.
+// Query parameters for nested fields use dot-separated field paths like "filter.genre", and repeated fields can be set via multiple query parameters.
+func createTranscodingHandler(route *transcodedRoute, r *rpc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := r.reqType.New()
+
+		if route.body != "" && len(c.Body()) > 0 {
+			body, target := c.Body(), req.Interface()
+			if route.bodyField != nil && route.bodyField.IsList() {
+				// A JSON array can only be unmarshalled as field of its message. The check prevents the body from setting other fields.
+				if !json.Valid(body) {
+					return sendStatus(c, status.New(codes.InvalidArgument, "Invalid request body: invalid JSON"))
+				}
+				body = []byte(`{"` + route.bodyField.JSONName() + `":` + string(body) + "}")
+			} else if route.bodyField != nil {
+				target = req.Mutable(route.bodyField).Message().Interface()
+			}
+			if err := protojson.Unmarshal(body, target); err != nil {
+				return sendStatus(c, status.New(codes.InvalidArgument, "Invalid request body: "+err.Error()))
+			}
+		}
+
+		for i, fields := range route.pathFields {
+			if err := setField(req, fields, []string{c.Params("v" + strconv.Itoa(i))}); err != nil {
+				return sendStatus(c, status.New(codes.InvalidArgument, "Invalid path: "+err.Error()))
+			}
+		}
+
+		if route.body != "*" {
+			// Multiple values for the same key are collected for repeated fields
+			var keys []string
+			values := make(map[string][]string)
+			c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+				if route.queryAliases != nil && len(value) == 0 {
+					return
+				}
+				if _, ok := values[string(key)]; !ok {
+					keys = append(keys, string(key))
+				}
+				values[string(key)] = append(values[string(key)], string(value))
+			})
+			for _, key := range keys {
+				if marshalOptionsParams[key] {
+					continue
+				}
+				path, keyValues := key, values[key]
+				if route.queryAliases != nil {
+					if path = route.queryAliases[key]; path == "" {
+						continue
+					}
+				}
+				fields, err := fieldPath(req.Descriptor(), path)
+				if err != nil {
+					return sendStatus(c, status.Newf(codes.InvalidArgument, "Invalid query parameter %q: %v", key, err))
+				} else if route.pathFieldSet[fields[len(fields)-1]] || (route.bodyField != nil && fields[0] == route.bodyField) {
+					return sendStatus(c, status.Newf(codes.InvalidArgument, "Invalid query parameter %q: The field is already set by the path or body", key))
+				}
+				if route.queryAliases != nil {
+					if fields[len(fields)-1].IsList() {
+						keyValues = strings.Split(strings.Join(keyValues, ","), ",")
+					} else {
+						// Only the first value counts, like for a single query parameter
+						keyValues = keyValues[:1]
+					}
+				}
+				if err = setField(req, fields, keyValues); err != nil {
+					return sendStatus(c, status.Newf(codes.InvalidArgument, "Invalid query parameter %q: %v", key, err))
+				}
+			}
+		}
+
+		if r.streamHandler != nil {
+			return sendStream(c, r, req.Interface())
+		}
+		res, err := r.callUnary(c.Context(), req.Interface())
+		if err != nil {
+			return sendStatus(c, status.Convert(err))
+		}
+		return sendMessage(c, res)
+	}
+}
+
+// setField sets the field at the end of the field path to the values, which must be a single value for non-repeated fields.
+func setField(m protoreflect.Message, fields []protoreflect.FieldDescriptor, values []string) error {
+	for _, fd := range fields[:len(fields)-1] {
+		if fd.IsList() || fd.IsMap() {
+			return fmt.Errorf("field %q isn't a message field", fd.Name())
+		}
+		m = m.Mutable(fd).Message()
+	}
+	fd := fields[len(fields)-1]
+	if fd.IsMap() || fd.Message() != nil {
+		return fmt.Errorf("field %q isn't a scalar field", fd.Name())
+	}
+	if fd.IsList() {
+		list := m.Mutable(fd).List()
+		for _, value := range values {
+			v, err := parseScalar(fd, value)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("field %q isn't repeated", fd.Name())
+	}
+	v, err := parseScalar(fd, values[0])
+	if err != nil {
+		return err
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+// parseScalar parses a value for the field like a JSON value, with enum values as number or name, which can also be lowercase or camel case like "tvSeries".
+func parseScalar(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByName(protoreflect.Name(s)); value != nil {
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		} else if value = fd.Enum().Values().ByName(protoreflect.Name(enumValueName(s))); value != nil {
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		}
+		i, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid value %q for enum %v", s, fd.Enum().Name())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i)), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		i, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(i)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		i, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(i), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(s, 32)
+		if err == nil && math.IsInf(f, 0) {
+			err = errors.New("value out of range")
+		}
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %v", fd.Kind())
+	}
+}
+
+// enumValueName converts an enum value name like "tv_series" or "tvSeries" (as in the IMDb dataset) to the style of the .proto file, like "TV_SERIES".
+func enumValueName(s string) string {
+	if strings.Contains(s, "_") {
+		return strings.ToUpper(s)
+	}
+	var sb strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// sendStatus sends the message of a gRPC status as plain text, with the HTTP status code that corresponds to the gRPC status code.
+func sendStatus(c *fiber.Ctx, s *status.Status) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.Status(httpStatusFromCode(s.Code())).SendString(s.Message())
+}
+
+// httpStatusFromCode maps gRPC status codes to HTTP status codes like gRPC-Gateway.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// Client Closed Request, as used by nginx
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
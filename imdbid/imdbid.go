@@ -0,0 +1,137 @@
+// Package imdbid converts IMDb IDs like "tt0068646" to DB keys and back.
+//
+// Next to storing the ID as is, there's a compact numeric encoding:
+// One byte for the kind of entity (title, name etc.), followed by the numeric part of the ID as 4 byte big-endian integer.
+// This shrinks the key size by almost half and, in contrast to the string IDs which have either 7 or 8 digits, makes the keys sort in numeric order,
+// which enables ordered range scans by ID.
+package imdbid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// KeyEncoding is an encoding of IMDb IDs as DB keys.
+type KeyEncoding string
+
+const (
+	// KeyEncodingString stores the ID as is, e.g. "tt0068646".
+	KeyEncodingString KeyEncoding = "string"
+	// KeyEncodingNumeric stores the ID as kind byte followed by a 4 byte big-endian integer.
+	KeyEncodingNumeric KeyEncoding = "numeric"
+)
+
+// NumericKeyLen is the length of keys with the numeric encoding.
+const NumericKeyLen = 5
+
+// ErrInvalidID is returned when an ID can't be encoded, for example because it has an unknown prefix or non-digit characters.
+var ErrInvalidID = errors.New("invalid IMDb ID")
+
+// Kinds of IMDb entities, as indicated by the ID's two letter prefix.
+// The values are used as first byte of numerically encoded keys, so they must never change.
+const (
+	KindTitle     byte = 1 // "tt"
+	KindName      byte = 2 // "nm"
+	KindCompany   byte = 3 // "co"
+	KindEvent     byte = 4 // "ev"
+	KindCharacter byte = 5 // "ch"
+	KindNews      byte = 6 // "ni"
+)
+
+var prefixes = [...]string{
+	KindTitle:     "tt",
+	KindName:      "nm",
+	KindCompany:   "co",
+	KindEvent:     "ev",
+	KindCharacter: "ch",
+	KindNews:      "ni",
+}
+
+// minDigits is the number of digits that IMDb pads the numeric part to.
+const minDigits = 7
+
+// ParseKeyEncoding converts a string like "numeric" to a KeyEncoding.
+func ParseKeyEncoding(s string) (KeyEncoding, error) {
+	switch KeyEncoding(s) {
+	case KeyEncodingString, KeyEncodingNumeric:
+		return KeyEncoding(s), nil
+	}
+	return "", fmt.Errorf("unknown key encoding: %v", s)
+}
+
+// AppendKey appends the DB key for the given ID to dst and returns the extended slice.
+// It doesn't allocate if dst has enough capacity.
+func (e KeyEncoding) AppendKey(dst []byte, id string) ([]byte, error) {
+	if e != KeyEncodingNumeric {
+		return append(dst, id...), nil
+	}
+	kind, number, err := Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, kind, byte(number>>24), byte(number>>16), byte(number>>8), byte(number)), nil
+}
+
+// Key returns the DB key for the given ID.
+func (e KeyEncoding) Key(id string) ([]byte, error) {
+	return e.AppendKey(make([]byte, 0, len(id)), id)
+}
+
+// ID converts a DB key back to the IMDb ID.
+func (e KeyEncoding) ID(key []byte) (string, error) {
+	if e != KeyEncodingNumeric {
+		return string(key), nil
+	}
+	if len(key) != NumericKeyLen || int(key[0]) >= len(prefixes) || prefixes[key[0]] == "" {
+		return "", ErrInvalidID
+	}
+	number := uint32(key[1])<<24 | uint32(key[2])<<16 | uint32(key[3])<<8 | uint32(key[4])
+	return Format(key[0], number), nil
+}
+
+// Parse splits an ID like "tt0068646" into its kind and number.
+// Only IDs in their canonical form are accepted, so that converting them back with Format leads to the same ID.
+func Parse(id string) (byte, uint32, error) {
+	if len(id) < 2+minDigits {
+		return 0, 0, ErrInvalidID
+	}
+	var kind byte
+	for k, prefix := range prefixes {
+		if prefix != "" && id[:2] == prefix {
+			kind = byte(k)
+			break
+		}
+	}
+	if kind == 0 {
+		return 0, 0, ErrInvalidID
+	}
+	digits := id[2:]
+	// Only IDs with more than the minimum number of digits can't have leading zeros
+	if len(digits) > minDigits && digits[0] == '0' {
+		return 0, 0, ErrInvalidID
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, 0, ErrInvalidID
+		}
+	}
+	number, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || number > math.MaxUint32 {
+		return 0, 0, ErrInvalidID
+	}
+	return kind, uint32(number), nil
+}
+
+// Format creates an ID like "tt0068646" from its kind and number.
+func Format(kind byte, number uint32) string {
+	digits := strconv.FormatUint(uint64(number), 10)
+	id := make([]byte, 0, 2+minDigits+3)
+	id = append(id, prefixes[kind]...)
+	for i := len(digits); i < minDigits; i++ {
+		id = append(id, '0')
+	}
+	id = append(id, digits...)
+	return string(id)
+}
@@ -0,0 +1,115 @@
+package imdbid
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		id     string
+		kind   byte
+		number uint32
+	}{
+		{"tt0068646", KindTitle, 68646},
+		{"tt0000001", KindTitle, 1},
+		{"tt0000000", KindTitle, 0},
+		{"tt9999999", KindTitle, 9999999},
+		{"tt10000000", KindTitle, 10000000},
+		{"tt4294967295", KindTitle, math.MaxUint32},
+		{"nm0000151", KindName, 151},
+		{"co0047120", KindCompany, 47120},
+		{"ev0000003", KindEvent, 3},
+		{"ch0000985", KindCharacter, 985},
+		{"ni64478367", KindNews, 64478367},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			kind, number, err := Parse(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kind != tt.kind || number != tt.number {
+				t.Errorf("expected kind %v and number %v, got %v and %v", tt.kind, tt.number, kind, number)
+			}
+			if id := Format(kind, number); id != tt.id {
+				t.Errorf("expected formatted ID %v, got %v", tt.id, id)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, id := range []string{
+		"",
+		"tt",
+		"tt006864",      // Too few digits
+		"tt00686460",    // Leading zero with more than 7 digits
+		"tt4294967296",  // Exceeds uint32
+		"tt99999999999", // Exceeds uint32
+		"xx0068646",     // Unknown prefix
+		"TT0068646",     // Prefixes are lowercase
+		"tt00686a6",
+		"tt-068646",
+		"tt+068646",
+		"tt 0068646",
+		"tt0068646 ",
+	} {
+		if kind, number, err := Parse(id); err != ErrInvalidID {
+			t.Errorf("expected ErrInvalidID for %q, got kind %v, number %v, error %v", id, kind, number, err)
+		}
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	for _, id := range []string{"tt0068646", "tt10000000", "nm0000151"} {
+		for _, encoding := range []KeyEncoding{KeyEncodingString, KeyEncodingNumeric} {
+			key, err := encoding.Key(id)
+			if err != nil {
+				t.Fatalf("couldn't encode %v with %v encoding: %v", id, encoding, err)
+			}
+			decoded, err := encoding.ID(key)
+			if err != nil {
+				t.Fatalf("couldn't decode %v key %x: %v", encoding, key, err)
+			}
+			if decoded != id {
+				t.Errorf("expected %v after %v round-trip, got %v", id, encoding, decoded)
+			}
+		}
+	}
+
+	key, err := KeyEncodingNumeric.Key("tt0068646")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{KindTitle, 0, 1, 0x0c, 0x26}; !bytes.Equal(key, expected) {
+		t.Errorf("expected numeric key %x, got %x", expected, key)
+	}
+	// Numeric keys sort in numeric order, unlike string keys with different numbers of digits
+	smaller, _ := KeyEncodingNumeric.Key("tt9999999")
+	larger, _ := KeyEncodingNumeric.Key("tt10000000")
+	if bytes.Compare(smaller, larger) >= 0 {
+		t.Errorf("expected key %x to sort before %x", smaller, larger)
+	}
+
+	if _, err = KeyEncodingNumeric.Key("foo"); err != ErrInvalidID {
+		t.Errorf("expected ErrInvalidID when encoding an invalid ID, got %v", err)
+	}
+	for _, key := range [][]byte{nil, {KindTitle, 0, 0, 0}, {0, 0, 0, 0, 1}, {KindNews + 1, 0, 0, 0, 1}, {KindTitle, 0, 0, 0, 1, 0}} {
+		if id, err := KeyEncodingNumeric.ID(key); err != ErrInvalidID {
+			t.Errorf("expected ErrInvalidID when decoding %x, got ID %q and error %v", key, id, err)
+		}
+	}
+}
+
+func TestParseKeyEncoding(t *testing.T) {
+	for _, s := range []string{"string", "numeric"} {
+		if encoding, err := ParseKeyEncoding(s); err != nil || string(encoding) != s {
+			t.Errorf("expected key encoding %v, got %v (error: %v)", s, encoding, err)
+		}
+	}
+	if _, err := ParseKeyEncoding("binary"); err == nil {
+		t.Error("expected an error for an unknown key encoding")
+	}
+}
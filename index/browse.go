@@ -0,0 +1,149 @@
+package index
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// List returns the IDs of up to limit titles that match the filter, in the order of their IDs.
+// It starts after the title with the numeric ID after, which can be 0 to start at the beginning.
+// It also returns whether there are more matching titles.
+//
+// Instead of checking all titles, it only checks the titles of the most selective secondary index for the filter.
+func (idx *Index) List(filter Filter, after uint32, limit int) ([]string, bool) {
+	// The docs are sorted by their ID
+	startDoc := uint32(sort.Search(len(idx.data.IDs), func(i int) bool { return idx.data.IDs[i] > after }))
+	it := idx.data.candidates(filter, startDoc)
+	matches := idx.data.matcher(filter)
+
+	var ids []string
+	for doc, ok := it.next(); ok; doc, ok = it.next() {
+		if !matches(doc) {
+			continue
+		}
+		if len(ids) == limit {
+			return ids, true
+		}
+		ids = append(ids, imdbid.Format(imdbid.KindTitle, idx.data.IDs[doc]))
+	}
+	return ids, false
+}
+
+// candidates returns an iterator over the docs from startDoc on that can match the filter, in the order of the docs.
+// It uses the secondary index with the fewest docs for the filter, so the docs still need to be checked against the whole filter.
+func (d *indexData) candidates(f Filter, startDoc uint32) *docIterator {
+	var lists [][]uint32
+	best := -1
+	consider := func(candidateLists [][]uint32) {
+		size := 0
+		for _, list := range candidateLists {
+			size += len(list)
+		}
+		if best == -1 || size < best {
+			lists, best = candidateLists, size
+		}
+	}
+
+	if len(f.TitleTypes) > 0 {
+		var typeLists [][]uint32
+		for i, titleType := range f.TitleTypes {
+			// The lists must not contain the same doc, so each title type's list is only added once
+			if titleType < 0 || int(titleType)+1 >= len(d.TypeOffsets) || containsTitleType(f.TitleTypes[:i], titleType) {
+				continue
+			}
+			typeLists = append(typeLists, d.TypeDocs[d.TypeOffsets[titleType]:d.TypeOffsets[titleType+1]])
+		}
+		consider(typeLists)
+	}
+	if f.Genre != "" {
+		var genreLists [][]uint32
+		for i, genre := range d.GenreNames {
+			if strings.EqualFold(genre, f.Genre) {
+				genreLists = append(genreLists, d.GenreDocs[d.GenreOffsets[i]:d.GenreOffsets[i+1]])
+				break
+			}
+		}
+		consider(genreLists)
+	}
+	if f.StartYearFrom != 0 || f.StartYearTo != 0 {
+		from := sort.Search(len(d.Years), func(i int) bool { return int(d.Years[i]) >= f.StartYearFrom })
+		to := len(d.Years)
+		if f.StartYearTo != 0 {
+			to = sort.Search(len(d.Years), func(i int) bool { return int(d.Years[i]) > f.StartYearTo })
+		}
+		var yearLists [][]uint32
+		for i := from; i < to; i++ {
+			yearLists = append(yearLists, d.YearDocs[d.YearOffsets[i]:d.YearOffsets[i+1]])
+		}
+		consider(yearLists)
+	}
+
+	if best == -1 {
+		return &docIterator{all: true, nextDoc: startDoc, docCount: uint32(len(d.IDs))}
+	}
+	it := &docIterator{}
+	for _, list := range lists {
+		// Skip the docs before startDoc
+		list = list[sort.Search(len(list), func(i int) bool { return list[i] >= startDoc }):]
+		if len(list) > 0 {
+			it.lists = append(it.lists, list)
+		}
+	}
+	heap.Init(it)
+	return it
+}
+
+func containsTitleType(titleTypes []pb.TitleType, titleType pb.TitleType) bool {
+	for _, other := range titleTypes {
+		if other == titleType {
+			return true
+		}
+	}
+	return false
+}
+
+// docIterator iterates over all docs or merges sorted lists of docs, which must not contain the same doc, in the order of the docs.
+// The lists are a min-heap by their first doc.
+type docIterator struct {
+	all      bool
+	nextDoc  uint32
+	docCount uint32
+
+	lists [][]uint32
+}
+
+func (it *docIterator) next() (uint32, bool) {
+	if it.all {
+		if it.nextDoc == it.docCount {
+			return 0, false
+		}
+		it.nextDoc++
+		return it.nextDoc - 1, true
+	}
+	if len(it.lists) == 0 {
+		return 0, false
+	}
+	doc := it.lists[0][0]
+	it.lists[0] = it.lists[0][1:]
+	if len(it.lists[0]) == 0 {
+		heap.Pop(it)
+	} else {
+		heap.Fix(it, 0)
+	}
+	return doc, true
+}
+
+// Implementation of heap.Interface
+func (it *docIterator) Len() int           { return len(it.lists) }
+func (it *docIterator) Less(i, j int) bool { return it.lists[i][0] < it.lists[j][0] }
+func (it *docIterator) Swap(i, j int)      { it.lists[i], it.lists[j] = it.lists[j], it.lists[i] }
+func (it *docIterator) Push(x interface{}) { it.lists = append(it.lists, x.([]uint32)) }
+func (it *docIterator) Pop() interface{} {
+	last := it.lists[len(it.lists)-1]
+	it.lists = it.lists[:len(it.lists)-1]
+	return last
+}
@@ -0,0 +1,308 @@
+package index
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// Builder collects titles and writes them as index file.
+type Builder struct {
+	data    indexData
+	entries []builderEntry
+	// Index of the genre names in data.GenreNames
+	genreBits map[string]int
+}
+
+type builderEntry struct {
+	title string
+	doc   uint32
+}
+
+// NewBuilder creates a new Builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		data: indexData{
+			Version: formatVersion,
+		},
+		genreBits: make(map[string]int),
+	}
+}
+
+// Add adds a title to the index.
+// votes is the number of IMDb votes of the title, which is used for ranking. It can be 0 if the ratings aren't known.
+func (b *Builder) Add(meta *pb.Meta, votes uint32) error {
+	kind, number, err := imdbid.Parse(meta.GetId())
+	if err != nil {
+		return err
+	} else if kind != imdbid.KindTitle {
+		return errors.New("not a title ID")
+	}
+	var genres uint64
+	for _, genre := range meta.GetGenres() {
+		bit, ok := b.genreBits[genre]
+		if !ok {
+			if len(b.data.GenreNames) == 64 {
+				return errors.New("too many different genres")
+			}
+			bit = len(b.data.GenreNames)
+			b.genreBits[genre] = bit
+			b.data.GenreNames = append(b.data.GenreNames, genre)
+		}
+		genres |= 1 << bit
+	}
+	doc := uint32(len(b.data.IDs))
+
+	b.data.IDs = append(b.data.IDs, number)
+	b.data.TitleTypes = append(b.data.TitleTypes, uint8(meta.GetTitleType()))
+	b.data.StartYears = append(b.data.StartYears, clampUint16(meta.GetStartYear()))
+	b.data.Votes = append(b.data.Votes, votes)
+	b.data.Genres = append(b.data.Genres, genres)
+	b.data.Runtimes = append(b.data.Runtimes, clampUint16(meta.GetRuntime()))
+	b.data.Adult = append(b.data.Adult, meta.GetIsAdult())
+
+	primaryTitle := Normalize(meta.GetPrimaryTitle())
+	if primaryTitle != "" {
+		b.entries = append(b.entries, builderEntry{title: primaryTitle, doc: doc})
+	}
+	// The original title is only set when it's different from the primary title, but it can still be the same after normalization
+	if originalTitle := Normalize(meta.GetOriginalTitle()); originalTitle != "" && originalTitle != primaryTitle {
+		b.entries = append(b.entries, builderEntry{title: originalTitle, doc: doc})
+	}
+	return nil
+}
+
+// Len returns the number of added titles.
+func (b *Builder) Len() int {
+	return len(b.data.IDs)
+}
+
+// WriteFile builds the index and writes it to the file at the given path.
+func (b *Builder) WriteFile(path string) error {
+	b.build()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, 1<<20)
+	if err = gob.NewEncoder(w).Encode(&b.data); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (b *Builder) build() {
+	b.sortDocs()
+	b.buildSecondaryIndexes()
+
+	sort.Slice(b.entries, func(i, j int) bool {
+		if b.entries[i].title != b.entries[j].title {
+			return b.entries[i].title < b.entries[j].title
+		}
+		return b.entries[i].doc < b.entries[j].doc
+	})
+
+	b.data.TitleOffsets = make([]uint32, 0, len(b.entries)+1)
+	b.data.EntryDocs = make([]uint32, 0, len(b.entries))
+	postings := make(map[string][]uint32)
+	for i, entry := range b.entries {
+		b.data.TitleOffsets = append(b.data.TitleOffsets, uint32(len(b.data.Titles)))
+		b.data.Titles = append(b.data.Titles, entry.title...)
+		b.data.EntryDocs = append(b.data.EntryDocs, entry.doc)
+		for _, word := range uniqueWords(entry.title) {
+			postings[word] = append(postings[word], uint32(i))
+		}
+	}
+	b.data.TitleOffsets = append(b.data.TitleOffsets, uint32(len(b.data.Titles)))
+	b.entries = nil
+
+	b.data.Words = make([]string, 0, len(postings))
+	for word := range postings {
+		b.data.Words = append(b.data.Words, word)
+	}
+	sort.Strings(b.data.Words)
+	b.data.PostingOffsets = make([]uint32, 0, len(b.data.Words)+1)
+	for _, word := range b.data.Words {
+		b.data.PostingOffsets = append(b.data.PostingOffsets, uint32(len(b.data.Postings)))
+		// Already sorted, because the entries are iterated in order
+		b.data.Postings = append(b.data.Postings, postings[word]...)
+	}
+	b.data.PostingOffsets = append(b.data.PostingOffsets, uint32(len(b.data.Postings)))
+
+	b.buildTrigrams()
+
+	b.buildTopPrefixes(0, uint32(len(b.data.EntryDocs)), 0)
+	b.data.TopOffsets = append(b.data.TopOffsets, uint32(len(b.data.TopDocs)))
+}
+
+// sortDocs sorts the docs by their ID, which is required for browsing titles in the order of their IDs.
+// The TSV file is already sorted by the ID as string, which is almost the same order.
+func (b *Builder) sortDocs() {
+	order := make([]uint32, len(b.data.IDs))
+	for i := range order {
+		order[i] = uint32(i)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return b.data.IDs[order[i]] < b.data.IDs[order[j]] })
+
+	newDocs := make([]uint32, len(order))
+	for newDoc, oldDoc := range order {
+		newDocs[oldDoc] = uint32(newDoc)
+	}
+	for i := range b.entries {
+		b.entries[i].doc = newDocs[b.entries[i].doc]
+	}
+	old := b.data
+	b.data.IDs = make([]uint32, len(order))
+	b.data.TitleTypes = make([]uint8, len(order))
+	b.data.StartYears = make([]uint16, len(order))
+	b.data.Votes = make([]uint32, len(order))
+	b.data.Genres = make([]uint64, len(order))
+	b.data.Runtimes = make([]uint16, len(order))
+	b.data.Adult = make([]bool, len(order))
+	for newDoc, oldDoc := range order {
+		b.data.IDs[newDoc] = old.IDs[oldDoc]
+		b.data.TitleTypes[newDoc] = old.TitleTypes[oldDoc]
+		b.data.StartYears[newDoc] = old.StartYears[oldDoc]
+		b.data.Votes[newDoc] = old.Votes[oldDoc]
+		b.data.Genres[newDoc] = old.Genres[oldDoc]
+		b.data.Runtimes[newDoc] = old.Runtimes[oldDoc]
+		b.data.Adult[newDoc] = old.Adult[oldDoc]
+	}
+}
+
+// buildSecondaryIndexes builds the indexes from title types, genres and start years to the docs.
+func (b *Builder) buildSecondaryIndexes() {
+	var typeDocs [][]uint32
+	genreDocs := make([][]uint32, len(b.data.GenreNames))
+	yearDocs := make(map[uint16][]uint32)
+	for doc := range b.data.IDs {
+		titleType := int(b.data.TitleTypes[doc])
+		for len(typeDocs) <= titleType {
+			typeDocs = append(typeDocs, nil)
+		}
+		typeDocs[titleType] = append(typeDocs[titleType], uint32(doc))
+		for i := range b.data.GenreNames {
+			if b.data.Genres[doc]&(1<<i) != 0 {
+				genreDocs[i] = append(genreDocs[i], uint32(doc))
+			}
+		}
+		if startYear := b.data.StartYears[doc]; startYear != 0 {
+			yearDocs[startYear] = append(yearDocs[startYear], uint32(doc))
+		}
+	}
+
+	b.data.TypeOffsets, b.data.TypeDocs = flatten(typeDocs)
+	b.data.GenreOffsets, b.data.GenreDocs = flatten(genreDocs)
+	for year := range yearDocs {
+		b.data.Years = append(b.data.Years, year)
+	}
+	sort.Slice(b.data.Years, func(i, j int) bool { return b.data.Years[i] < b.data.Years[j] })
+	lists := make([][]uint32, len(b.data.Years))
+	for i, year := range b.data.Years {
+		lists[i] = yearDocs[year]
+	}
+	b.data.YearOffsets, b.data.YearDocs = flatten(lists)
+}
+
+// flatten concatenates the lists and returns the start of each list, with one more element for the end of the last list.
+func flatten(lists [][]uint32) ([]uint32, []uint32) {
+	offsets := make([]uint32, 0, len(lists)+1)
+	var flat []uint32
+	for _, list := range lists {
+		offsets = append(offsets, uint32(len(flat)))
+		flat = append(flat, list...)
+	}
+	offsets = append(offsets, uint32(len(flat)))
+	return offsets, flat
+}
+
+// buildTrigrams builds the trigram index over all words.
+func (b *Builder) buildTrigrams() {
+	trigramWords := make(map[uint32][]uint32)
+	for i, word := range b.data.Words {
+		for _, trigram := range uniqueTrigrams(word, nil) {
+			trigramWords[trigram] = append(trigramWords[trigram], uint32(i))
+		}
+	}
+	b.data.Trigrams = make([]uint32, 0, len(trigramWords))
+	for trigram := range trigramWords {
+		b.data.Trigrams = append(b.data.Trigrams, trigram)
+	}
+	sort.Slice(b.data.Trigrams, func(i, j int) bool { return b.data.Trigrams[i] < b.data.Trigrams[j] })
+	b.data.TrigramOffsets = make([]uint32, 0, len(b.data.Trigrams)+1)
+	for _, trigram := range b.data.Trigrams {
+		b.data.TrigramOffsets = append(b.data.TrigramOffsets, uint32(len(b.data.TrigramWords)))
+		// Already sorted, because the words are iterated in order
+		b.data.TrigramWords = append(b.data.TrigramWords, trigramWords[trigram]...)
+	}
+	b.data.TrigramOffsets = append(b.data.TrigramOffsets, uint32(len(b.data.TrigramWords)))
+}
+
+// buildTopPrefixes stores the most popular docs for the title prefix of the given length that the entries from lo to hi have in common,
+// if there are too many entries to find them quickly when suggesting titles.
+// It then continues recursively with the longer prefixes.
+// Because the entries are sorted, the prefixes are added in sorted order.
+func (b *Builder) buildTopPrefixes(lo, hi uint32, prefixLen int) {
+	if hi-lo <= maxSuggestScan {
+		return
+	}
+	if prefixLen > 0 {
+		b.data.TopPrefixes = append(b.data.TopPrefixes, b.data.title(lo)[:prefixLen])
+		b.data.TopOffsets = append(b.data.TopOffsets, uint32(len(b.data.TopDocs)))
+		b.data.TopDocs = append(b.data.TopDocs, b.data.topDocs(lo, hi, MaxSuggestions)...)
+	}
+	// Entries that are as long as the prefix are sorted first. They don't have a longer prefix.
+	for lo < hi && len(b.data.title(lo)) == prefixLen {
+		lo++
+	}
+	// Group the remaining entries by the next byte
+	for lo < hi {
+		next := b.data.Titles[b.data.TitleOffsets[lo]+uint32(prefixLen)]
+		end := lo + 1
+		for end < hi && b.data.Titles[b.data.TitleOffsets[end]+uint32(prefixLen)] == next {
+			end++
+		}
+		b.buildTopPrefixes(lo, end, prefixLen+1)
+		lo = end
+	}
+}
+
+// uniqueWords splits a normalized title into its words, without duplicates.
+func uniqueWords(title string) []string {
+	words := strings.Fields(title)
+	unique := words[:0]
+	for i, word := range words {
+		duplicate := false
+		for _, other := range words[:i] {
+			if word == other {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, word)
+		}
+	}
+	return unique
+}
+
+func clampUint16(i int32) uint16 {
+	if i < 0 {
+		return 0
+	} else if i > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(i)
+}
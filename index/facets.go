@@ -0,0 +1,130 @@
+package index
+
+import (
+	"sort"
+
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// Facets are the numbers of titles per title type, genre, decade and adult flag.
+type Facets struct {
+	Total      int
+	TitleTypes []TitleTypeCount // In the order of the title type values
+	Genres     []GenreCount     // Most titles first
+	Decades    []DecadeCount    // Oldest first. Titles without start year aren't counted.
+	Adult      int
+	NonAdult   int
+}
+
+// TitleTypeCount is the number of titles of a title type.
+type TitleTypeCount struct {
+	TitleType pb.TitleType
+	Count     int
+}
+
+// GenreCount is the number of titles of a genre.
+type GenreCount struct {
+	Genre string
+	Count int
+}
+
+// DecadeCount is the number of titles that started in a decade, like 1980 for the years 1980 to 1989.
+type DecadeCount struct {
+	Decade int
+	Count  int
+}
+
+// Facets counts the titles that match the filter per title type, genre, decade and adult flag.
+// Only counts that aren't 0 are returned. The returned Facets must not be modified.
+//
+// Without filter the counts are precomputed when loading the index.
+// With filter only the titles of the most selective secondary index for the filter are checked, like when listing titles.
+func (idx *Index) Facets(filter Filter) Facets {
+	if filter.isEmpty() {
+		return idx.allFacets
+	}
+
+	typeCounts := make([]int, len(pb.TitleType_name))
+	genreCounts := make([]int, len(idx.data.GenreNames))
+	decadeCounts := make(map[int]int)
+	var facets Facets
+	it := idx.data.candidates(filter, 0)
+	matches := idx.data.matcher(filter)
+	for doc, ok := it.next(); ok; doc, ok = it.next() {
+		if !matches(doc) {
+			continue
+		}
+		facets.Total++
+		if titleType := int(idx.data.TitleTypes[doc]); titleType < len(typeCounts) {
+			typeCounts[titleType]++
+		}
+		for i, genres := 0, idx.data.Genres[doc]; genres != 0; i, genres = i+1, genres>>1 {
+			if genres&1 != 0 {
+				genreCounts[i]++
+			}
+		}
+		if startYear := int(idx.data.StartYears[doc]); startYear != 0 {
+			decadeCounts[startYear/10*10]++
+		}
+		if idx.data.Adult[doc] {
+			facets.Adult++
+		}
+	}
+	facets.NonAdult = facets.Total - facets.Adult
+	facets.setCounts(typeCounts, idx.data.GenreNames, genreCounts, decadeCounts)
+	return facets
+}
+
+// countAllFacets counts all titles, using the lengths of the secondary indexes where possible.
+func (d *indexData) countAllFacets() Facets {
+	facets := Facets{
+		Total: len(d.IDs),
+	}
+	typeCounts := make([]int, len(pb.TitleType_name))
+	for i := 0; i+1 < len(d.TypeOffsets) && i < len(typeCounts); i++ {
+		typeCounts[i] = int(d.TypeOffsets[i+1] - d.TypeOffsets[i])
+	}
+	genreCounts := make([]int, len(d.GenreNames))
+	for i := range genreCounts {
+		genreCounts[i] = int(d.GenreOffsets[i+1] - d.GenreOffsets[i])
+	}
+	decadeCounts := make(map[int]int)
+	for i, year := range d.Years {
+		decadeCounts[int(year)/10*10] += int(d.YearOffsets[i+1] - d.YearOffsets[i])
+	}
+	for _, adult := range d.Adult {
+		if adult {
+			facets.Adult++
+		}
+	}
+	facets.NonAdult = facets.Total - facets.Adult
+	facets.setCounts(typeCounts, d.GenreNames, genreCounts, decadeCounts)
+	return facets
+}
+
+func (f *Facets) setCounts(typeCounts []int, genreNames []string, genreCounts []int, decadeCounts map[int]int) {
+	for titleType, count := range typeCounts {
+		if count != 0 {
+			f.TitleTypes = append(f.TitleTypes, TitleTypeCount{TitleType: pb.TitleType(titleType), Count: count})
+		}
+	}
+	for i, count := range genreCounts {
+		if count != 0 {
+			f.Genres = append(f.Genres, GenreCount{Genre: genreNames[i], Count: count})
+		}
+	}
+	sort.Slice(f.Genres, func(i, j int) bool {
+		if f.Genres[i].Count != f.Genres[j].Count {
+			return f.Genres[i].Count > f.Genres[j].Count
+		}
+		return f.Genres[i].Genre < f.Genres[j].Genre
+	})
+	for decade, count := range decadeCounts {
+		f.Decades = append(f.Decades, DecadeCount{Decade: decade, Count: count})
+	}
+	sort.Slice(f.Decades, func(i, j int) bool { return f.Decades[i].Decade < f.Decades[j].Decade })
+}
+
+func (f Filter) isEmpty() bool {
+	return len(f.TitleTypes) == 0 && f.StartYearFrom == 0 && f.StartYearTo == 0 && f.Genre == "" && f.MinRuntime == 0 && f.Adult == nil
+}
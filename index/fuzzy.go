@@ -0,0 +1,232 @@
+package index
+
+import (
+	"sort"
+	"strings"
+)
+
+// FuzzySearch returns the titles that match the query and filter despite typos, ranked by their similarity to the query, paginated with offset and limit.
+// It also returns the total number of matching titles.
+//
+// A title matches if its primary or original title contains a similar word for each word of the query.
+// Words are similar if their edit distance (with transpositions of adjacent letters counting as one edit) is small enough for their length:
+// Words of up to 3 letters must match exactly, words of up to 7 letters can have one edit and longer words two.
+// Similar words are found via the trigram index. Trigrams that more than maxTrigramWords words contain are ignored,
+// and of the words that are similar to a query word, only the most similar ones with up to maxMergedPostings entries are used.
+func (idx *Index) FuzzySearch(query string, filter Filter, offset, limit int) ([]Result, int) {
+	query = Normalize(query)
+	words := uniqueWords(query)
+	if len(words) == 0 {
+		return nil, 0
+	}
+
+	// Sum of the best word similarities per entry, for the entries that contain similar words for all query words so far
+	var similarities map[uint32]float64
+	for i, word := range words {
+		entrySimilarities := make(map[uint32]float64)
+		for _, w := range idx.limitPostings(idx.similarWords(word)) {
+			for _, entry := range idx.data.Postings[idx.data.PostingOffsets[w.word]:idx.data.PostingOffsets[w.word+1]] {
+				if i > 0 {
+					if _, ok := similarities[entry]; !ok {
+						continue
+					}
+				}
+				if w.similarity > entrySimilarities[entry] {
+					entrySimilarities[entry] = w.similarity
+				}
+			}
+		}
+		for entry := range entrySimilarities {
+			entrySimilarities[entry] += similarities[entry]
+		}
+		similarities = entrySimilarities
+		if len(similarities) == 0 {
+			return nil, 0
+		}
+	}
+
+	// Score the entries and keep the best score per doc
+	matches := idx.data.matcher(filter)
+	scores := make(map[uint32]docScore, len(similarities))
+	for entry, similarity := range similarities {
+		doc := idx.data.EntryDocs[entry]
+		if !matches(doc) {
+			continue
+		}
+		title := idx.data.title(entry)
+		score, match := 1.0, MatchExact
+		if title != query {
+			// Like for the normal search, titles that the query covers more are ranked higher
+			coverage := float64(len(query)) / float64(len(title))
+			if coverage > 1 {
+				coverage = 1
+			}
+			// Only an exact match has the score 1
+			score, match = 0.9*similarity/float64(len(words))*(0.7+0.3*coverage), MatchFuzzy
+		}
+		if score > scores[doc].score {
+			scores[doc] = docScore{score: score, match: match}
+		}
+	}
+	return idx.page(scores, offset, limit)
+}
+
+// maxTrigramWords is the maximum number of words of a trigram for using it to find similar words.
+// Very common trigrams like " th" barely narrow down the candidates, but would have to be counted for a large part of all words.
+const maxTrigramWords = 20000
+
+type similarWord struct {
+	word       uint32
+	similarity float64
+}
+
+// limitPostings returns the most similar words, as many as fit into maxMergedPostings entries together.
+func (idx *Index) limitPostings(similar map[uint32]float64) []similarWord {
+	words := make([]similarWord, 0, len(similar))
+	for w, similarity := range similar {
+		words = append(words, similarWord{word: w, similarity: similarity})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].similarity != words[j].similarity {
+			return words[i].similarity > words[j].similarity
+		}
+		return words[i].word < words[j].word
+	})
+	postingCount := 0
+	for i, w := range words {
+		postingCount += idx.postingCount(int(w.word), int(w.word)+1)
+		if postingCount > maxMergedPostings {
+			return words[:i]
+		}
+	}
+	return words
+}
+
+// maxEditDistance returns the edit distance that's tolerated for a word with the given number of letters.
+func maxEditDistance(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// similarWords returns the indexes of the words that are similar to the word, with their similarity between 0 and 1.
+func (idx *Index) similarWords(word string) map[uint32]float64 {
+	similar := make(map[uint32]float64)
+	runes := []rune(word)
+	maxDistance := maxEditDistance(len(runes))
+	if maxDistance == 0 {
+		if i := sort.SearchStrings(idx.data.Words, word); i < len(idx.data.Words) && idx.data.Words[i] == word {
+			similar[uint32(i)] = 1
+		}
+		return similar
+	}
+
+	// Each edit changes at most 4 trigrams (a transposition), so similar words must share at least this many of the used trigrams with the word.
+	// Words that don't share any used trigram aren't found, which only affects very short words or words with only common trigrams.
+	trigrams := uniqueTrigrams(word, nil)
+	used := 0
+	shared := make(map[uint32]int)
+	for _, trigram := range trigrams {
+		i := sort.Search(len(idx.data.Trigrams), func(i int) bool { return idx.data.Trigrams[i] >= trigram })
+		if i < len(idx.data.Trigrams) && idx.data.Trigrams[i] == trigram && idx.data.TrigramOffsets[i+1]-idx.data.TrigramOffsets[i] > maxTrigramWords {
+			continue
+		}
+		// Trigrams that no word contains still count for the minimum number of shared trigrams
+		used++
+		if i == len(idx.data.Trigrams) || idx.data.Trigrams[i] != trigram {
+			continue
+		}
+		for _, w := range idx.data.TrigramWords[idx.data.TrigramOffsets[i]:idx.data.TrigramOffsets[i+1]] {
+			shared[w]++
+		}
+	}
+	minShared := used - 4*maxDistance
+	for w, count := range shared {
+		if count < minShared {
+			continue
+		}
+		other := []rune(idx.data.Words[w])
+		if abs(len(other)-len(runes)) > maxDistance {
+			continue
+		}
+		distance := editDistance(runes, other)
+		if distance > maxDistance {
+			continue
+		}
+		maxLen := len(runes)
+		if len(other) > maxLen {
+			maxLen = len(other)
+		}
+		similar[w] = 1 - float64(distance)/float64(maxLen)
+	}
+	return similar
+}
+
+// uniqueTrigrams appends the trigrams of the word, with a space before and after it, to buf without duplicates.
+// Each trigram is stored as its three bytes in an uint32.
+func uniqueTrigrams(word string, buf []uint32) []uint32 {
+	padded := " " + strings.TrimSpace(word) + " "
+	for i := 0; i+3 <= len(padded); i++ {
+		trigram := uint32(padded[i])<<16 | uint32(padded[i+1])<<8 | uint32(padded[i+2])
+		duplicate := false
+		for _, other := range buf {
+			if other == trigram {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			buf = append(buf, trigram)
+		}
+	}
+	return buf
+}
+
+// editDistance returns the optimal string alignment distance of a and b,
+// which is the Levenshtein distance with transpositions of adjacent letters as additional edit, like in "Godfahter".
+func editDistance(a, b []rune) int {
+	// Three rows of the distance matrix are enough
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prevPrev[j-2]+1 < curr[j] {
+				curr[j] = prevPrev[j-2] + 1
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
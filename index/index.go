@@ -0,0 +1,158 @@
+// Package index implements the title index, which the importer builds from the IMDb dataset and the service uses for searching titles.
+//
+// The index contains the normalized primary and original titles of all titles, sorted, and an inverted index from the words of the titles to the titles.
+// All data is stored in a few big slices instead of millions of small objects, to keep the memory usage and load time low.
+package index
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// formatVersion is the version of the index file format. It must be increased on incompatible changes.
+const formatVersion = 4
+
+// Index is a loaded title index. It's safe for concurrent use.
+type Index struct {
+	data indexData
+	// Facets of all titles
+	allFacets Facets
+}
+
+// indexData is the content of an index file.
+// The docs are the indexed IMDb titles, the entries are their normalized titles (one or two per doc).
+type indexData struct {
+	Version int
+
+	// Doc columns, sorted by the ID
+	IDs        []uint32 // Numeric part of the IMDb ID
+	TitleTypes []uint8
+	StartYears []uint16
+	Votes      []uint32 // Number of IMDb votes, 0 if no ratings were imported
+	Genres     []uint64 // Bit i is set if the title has the i-th genre of GenreNames
+	GenreNames []string
+	Runtimes   []uint16 // In minutes
+	Adult      []bool
+
+	// Secondary indexes from title types, genres and start years to the sorted docs, for browsing titles
+	TypeOffsets  []uint32 // Start of the docs of the title type with the value i, with one more element for the end of the last title type's docs
+	TypeDocs     []uint32
+	GenreOffsets []uint32 // Start of the docs of the i-th genre of GenreNames, with one more element for the end of the last genre's docs
+	GenreDocs    []uint32
+	Years        []uint16 // Sorted, without 0
+	YearOffsets  []uint32 // Start of the docs of the i-th year, with one more element for the end of the last year's docs
+	YearDocs     []uint32
+
+	// Entries, sorted by their title
+	Titles       []byte   // All normalized titles, concatenated
+	TitleOffsets []uint32 // Start of the i-th title in Titles, with one more element for the end of the last title
+	EntryDocs    []uint32 // Doc of the i-th entry
+
+	// Inverted index from words to entries
+	Words          []string // Sorted
+	PostingOffsets []uint32 // Start of the postings of the i-th word, with one more element for the end of the last word's postings
+	Postings       []uint32 // Sorted entry indexes per word
+
+	// Trigram index from the trigrams of the words (with a space before and after the word) to the words, for fuzzy search
+	Trigrams       []uint32 // Sorted, three bytes per trigram
+	TrigramOffsets []uint32 // Start of the words of the i-th trigram, with one more element for the end of the last trigram's words
+	TrigramWords   []uint32 // Sorted word indexes per trigram
+
+	// Most popular docs for title prefixes that too many entries start with, for quick suggestions
+	TopPrefixes []string // Sorted
+	TopOffsets  []uint32 // Start of the docs of the i-th prefix, with one more element for the end of the last prefix's docs
+	TopDocs     []uint32 // Docs per prefix, most popular first
+}
+
+// Load reads the index file at the given path.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var data indexData
+	if err = gob.NewDecoder(bufio.NewReader(f)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("couldn't decode index file: %w", err)
+	}
+	if data.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported index format version %v, expected %v", data.Version, formatVersion)
+	}
+	return &Index{
+		data:      data,
+		allFacets: data.countAllFacets(),
+	}, nil
+}
+
+// Len returns the number of indexed titles.
+func (idx *Index) Len() int {
+	return len(idx.data.IDs)
+}
+
+// GenreNames returns the names of all genres of the indexed titles, sorted.
+func (idx *Index) GenreNames() []string {
+	genreNames := append([]string(nil), idx.data.GenreNames...)
+	sort.Strings(genreNames)
+	return genreNames
+}
+
+func (idx *Index) id(doc uint32) string {
+	return imdbid.Format(imdbid.KindTitle, idx.data.IDs[doc])
+}
+
+func (d *indexData) title(entry uint32) string {
+	return string(d.titleBytes(entry))
+}
+
+func (d *indexData) titleBytes(entry uint32) []byte {
+	return d.Titles[d.TitleOffsets[entry]:d.TitleOffsets[entry+1]]
+}
+
+// popularity is how well-known a title is, for ranking titles that match a query equally well.
+// It's the number of votes if ratings were imported, with a heuristic based on the title type and year as tie-breaker, which is always below 1.
+func (d *indexData) popularity(doc uint32) float64 {
+	heuristic := titleTypeWeights[pb.TitleType(d.TitleTypes[doc])]
+	// Recent titles are more likely to be looked for than old ones, but only slightly
+	yearFactor := 0.5
+	if startYear := d.StartYears[doc]; startYear != 0 {
+		yearFactor = 0.5 + 0.49*clampFloat(float64(int(startYear)-1900)/float64(currentYear-1900))
+	}
+	return float64(d.Votes[doc]) + 0.999*heuristic*yearFactor
+}
+
+// currentYear is only determined once, which is precise enough for the popularity heuristic.
+var currentYear = time.Now().Year()
+
+func clampFloat(f float64) float64 {
+	if f < 0 {
+		return 0
+	} else if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// titleTypeWeights reflect how likely users look for a title of the type.
+var titleTypeWeights = map[pb.TitleType]float64{
+	pb.TitleType_MOVIE:          1,
+	pb.TitleType_TV_SERIES:      0.9,
+	pb.TitleType_TV_MINI_SERIES: 0.8,
+	pb.TitleType_TV_MOVIE:       0.6,
+	pb.TitleType_TV_SPECIAL:     0.5,
+	pb.TitleType_VIDEO:          0.4,
+	pb.TitleType_SHORT:          0.3,
+	pb.TitleType_TV_SHORT:       0.3,
+	pb.TitleType_VIDEO_GAME:     0.3,
+	pb.TitleType_RADIO_SERIES:   0.2,
+	pb.TitleType_AUDIOBOOK:      0.2,
+	pb.TitleType_TV_EPISODE:     0.1,
+	pb.TitleType_EPISODE:        0.1,
+}
@@ -0,0 +1,441 @@
+package index
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/deflix-tv/imdb2meta/imdbid"
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// fillerCount is the number of generated titles, which must be more than maxSuggestScan for testing the precomputed suggestions.
+const fillerCount = 1500
+
+// testTitles are added to the test index, with their number of votes.
+var testTitles = []struct {
+	meta  *pb.Meta
+	votes uint32
+}{
+	{&pb.Meta{Id: "tt0068646", PrimaryTitle: "The Godfather", StartYear: 1972, Runtime: 175, Genres: []string{"Crime", "Drama"}}, 1900000},
+	{&pb.Meta{Id: "tt0071562", PrimaryTitle: "The Godfather Part II", StartYear: 1974, Runtime: 202, Genres: []string{"Crime", "Drama"}}, 1300000},
+	{&pb.Meta{Id: "tt0099674", PrimaryTitle: "The Godfather Part III", StartYear: 1990, Runtime: 162, Genres: []string{"Crime", "Drama"}}, 400000},
+	{&pb.Meta{Id: "tt0076759", PrimaryTitle: "Star Wars", OriginalTitle: "Star Wars: Episode IV - A New Hope", StartYear: 1977, Runtime: 121, Genres: []string{"Action", "Adventure", "Fantasy"}}, 1400000},
+	{&pb.Meta{Id: "tt0060028", TitleType: pb.TitleType_TV_SERIES, PrimaryTitle: "Star Trek", StartYear: 1966, Runtime: 50, Genres: []string{"Action", "Adventure", "Sci-Fi"}}, 90000},
+	{&pb.Meta{Id: "tt0092455", TitleType: pb.TitleType_TV_SERIES, PrimaryTitle: "Star Trek: The Next Generation", StartYear: 1987, Runtime: 44, Genres: []string{"Action", "Adventure", "Drama"}}, 150000},
+	{&pb.Meta{Id: "tt0211915", PrimaryTitle: "Amélie", OriginalTitle: "Le fabuleux destin d'Amélie Poulain", StartYear: 2001, Runtime: 122, Genres: []string{"Comedy", "Romance"}}, 780000},
+	{&pb.Meta{Id: "tt1254207", TitleType: pb.TitleType_SHORT, PrimaryTitle: "Big Buck Bunny", StartYear: 2008, Runtime: 10, Genres: []string{"Animation", "Comedy", "Short"}}, 0},
+	{&pb.Meta{Id: "tt0000001", TitleType: pb.TitleType_SHORT, PrimaryTitle: "Carmencita", StartYear: 1894, Runtime: 1, Genres: []string{"Documentary", "Short"}}, 2000},
+	{&pb.Meta{Id: "tt0086250", PrimaryTitle: "Scarface", StartYear: 1983, Runtime: 170, Genres: []string{"Crime", "Drama"}}, 870000},
+	{&pb.Meta{Id: "tt10000000", PrimaryTitle: "Stars", IsAdult: true, StartYear: 2010, Runtime: 80}, 10},
+	{&pb.Meta{Id: "tt0000002", TitleType: pb.TitleType_SHORT, PrimaryTitle: "Le clown et ses chiens"}, 0},
+}
+
+// buildTestIndex writes an index with the test titles and generated titles "Filler <n>" with n votes, and loads it.
+func buildTestIndex(t *testing.T) *Index {
+	t.Helper()
+	b := NewBuilder()
+	for _, title := range testTitles {
+		if err := b.Add(title.meta, title.votes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= fillerCount; i++ {
+		meta := &pb.Meta{
+			Id:           "tt" + strconv.Itoa(2000000+i),
+			TitleType:    pb.TitleType_TV_EPISODE,
+			PrimaryTitle: "Filler " + strconv.Itoa(i),
+			StartYear:    int32(1900 + i%100),
+		}
+		if err := b.Add(meta, uint32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Len() != len(testTitles)+fillerCount {
+		t.Fatalf("expected %v titles in the builder, got %v", len(testTitles)+fillerCount, b.Len())
+	}
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	if err := b.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestLoad(t *testing.T) {
+	idx := buildTestIndex(t)
+	if idx.Len() != len(testTitles)+fillerCount {
+		t.Errorf("expected %v titles, got %v", len(testTitles)+fillerCount, idx.Len())
+	}
+	expectedGenres := []string{"Action", "Adventure", "Animation", "Comedy", "Crime", "Documentary", "Drama", "Fantasy", "Romance", "Sci-Fi", "Short"}
+	if genres := idx.GenreNames(); !reflect.DeepEqual(genres, expectedGenres) {
+		t.Errorf("expected genres %v, got %v", expectedGenres, genres)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.gob")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestBuilderAddInvalid(t *testing.T) {
+	b := NewBuilder()
+	for _, id := range []string{"", "tt123", "nm0000151"} {
+		if err := b.Add(&pb.Meta{Id: id, PrimaryTitle: "Foo"}, 0); err == nil {
+			t.Errorf("expected an error for ID %q", id)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"The Lord of the Rings: The Fellowship of the Ring", "lord of the rings the fellowship of the ring"},
+		{"Le fabuleux destin d'Amélie Poulain", "fabuleux destin damelie poulain"},
+		{"Schindler's List", "schindlers list"},
+		{"Fast & Furious", "fast and furious"},
+		{"Die Hard", "die hard"},
+		{"Straße", "strasse"},
+		{"  WALL·E  ", "wall e"},
+		{"The", "the"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if normalized := Normalize(tt.title); normalized != tt.expected {
+			t.Errorf("expected %q for %q, got %q", tt.expected, tt.title, normalized)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := buildTestIndex(t)
+	boolTrue := true
+	tests := []struct {
+		name          string
+		query         string
+		filter        Filter
+		expectedIDs   []string
+		expectedTotal int
+	}{
+		{"exact match first", "the godfather", Filter{}, []string{"tt0068646", "tt0071562", "tt0099674"}, 3},
+		{"ranked by popularity", "godfather part", Filter{}, []string{"tt0071562", "tt0099674"}, 2},
+		{"prefix of last word", "godfather par", Filter{}, []string{"tt0071562", "tt0099674"}, 2},
+		{"words in any order", "part godfather", Filter{}, []string{"tt0071562", "tt0099674"}, 2},
+		{"original title", "fabuleux destin", Filter{}, []string{"tt0211915"}, 1},
+		{"diacritics", "AMÉLIE", Filter{}, []string{"tt0211915"}, 1},
+		{"article of original title", "le clown", Filter{}, []string{"tt0000002"}, 1},
+		{"no match", "godfather wars", Filter{}, nil, 0},
+		{"empty query", " ", Filter{}, nil, 0},
+		// A single letter isn't expanded as prefix, but the titles of the other words are checked
+		{"short prefix after other words", "star t", Filter{}, []string{"tt0060028", "tt0092455"}, 2},
+		{"short prefix alone matches whole word", "s", Filter{}, nil, 0},
+		// Prefix matches are ranked by how much of the title the query covers, then by popularity
+		{"prefix", "sta", Filter{}, []string{"tt10000000", "tt0076759", "tt0060028", "tt0092455"}, 4},
+		{"title type filter", "star", Filter{TitleTypes: []pb.TitleType{pb.TitleType_TV_SERIES}}, []string{"tt0060028", "tt0092455"}, 2},
+		{"year filter", "godfather", Filter{StartYearFrom: 1973, StartYearTo: 1990}, []string{"tt0071562", "tt0099674"}, 2},
+		{"genre filter", "star", Filter{Genre: "sci-fi"}, []string{"tt0060028"}, 1},
+		{"unknown genre", "star", Filter{Genre: "Western"}, nil, 0},
+		{"runtime filter", "star", Filter{MinRuntime: 100}, []string{"tt0076759"}, 1},
+		{"adult filter", "star", Filter{Adult: &boolTrue}, []string{"tt10000000"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, total := idx.Search(tt.query, tt.filter, 0, 10)
+			if ids := resultIDs(results); !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("expected IDs %v, got %v", tt.expectedIDs, ids)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("expected total %v, got %v", tt.expectedTotal, total)
+			}
+		})
+	}
+
+	t.Run("scores", func(t *testing.T) {
+		results, _ := idx.Search("the godfather", Filter{}, 0, 10)
+		if results[0].Score != 1 || results[0].Match != MatchExact {
+			t.Errorf("expected an exact match with score 1, got %+v", results[0])
+		}
+		if results[1].Match != MatchPrefix || results[1].Score >= 1 {
+			t.Errorf("expected a prefix match with a lower score, got %+v", results[1])
+		}
+		if results[0].TitleType != pb.TitleType_MOVIE || results[0].StartYear != 1972 {
+			t.Errorf("expected the title type and start year of the title, got %+v", results[0])
+		}
+		results, _ = idx.Search("part", Filter{}, 0, 10)
+		if len(results) == 0 || results[0].Match != MatchWords {
+			t.Errorf("expected a word match, got %+v", results)
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		// "Filler 1", "Filler 10" to "Filler 19", "Filler 100" to "Filler 199" and "Filler 1000" to "Filler 1500"
+		const expectedTotal = 1 + 10 + 100 + 501
+		seen := make(map[string]bool)
+		for offset := 0; ; offset += 50 {
+			results, total := idx.Search("filler 1", Filter{}, offset, 50)
+			if total != expectedTotal {
+				t.Fatalf("expected total %v, got %v", expectedTotal, total)
+			}
+			if len(results) == 0 {
+				break
+			}
+			for _, id := range resultIDs(results) {
+				if seen[id] {
+					t.Errorf("result %v is on multiple pages", id)
+				}
+				seen[id] = true
+			}
+		}
+		if len(seen) != expectedTotal {
+			t.Errorf("expected %v results over all pages, got %v", expectedTotal, len(seen))
+		}
+		results, total := idx.Search("filler", Filter{}, fillerCount-1, 10)
+		if total != fillerCount || len(results) != 1 {
+			t.Errorf("expected the last of %v results, got %v of %v", fillerCount, len(results), total)
+		}
+	})
+}
+
+func TestExactEntries(t *testing.T) {
+	idx := buildTestIndex(t)
+	for _, tt := range []struct {
+		query         string
+		expectedCount int
+	}{
+		{"scarface", 1},
+		{"stars", 1},
+		{"star", 0},
+		{"filler", 0},
+		{"filler 1", 1},
+	} {
+		entries := idx.exactEntries(tt.query)
+		if len(entries) != tt.expectedCount {
+			t.Errorf("expected %v entries for %q, got %v", tt.expectedCount, tt.query, len(entries))
+		}
+		for _, entry := range entries {
+			if title := idx.data.title(entry); title != tt.query {
+				t.Errorf("expected title %q, got %q", tt.query, title)
+			}
+		}
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	idx := buildTestIndex(t)
+	tests := []struct {
+		name        string
+		query       string
+		filter      Filter
+		expectedIDs []string
+	}{
+		{"transposition", "godfahter", Filter{}, []string{"tt0068646", "tt0071562", "tt0099674"}},
+		{"substitution", "scarfase", Filter{}, []string{"tt0086250"}},
+		{"two edits in long word", "carmenzitta", Filter{}, []string{"tt0000001"}},
+		{"too many edits", "garmenzitta", Filter{}, nil},
+		{"short words must match exactly", "sar wars", Filter{}, nil},
+		{"multiple words", "star wasr", Filter{}, []string{"tt0076759"}},
+		{"filter", "godfahter", Filter{StartYearFrom: 1990}, []string{"tt0099674"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, total := idx.FuzzySearch(tt.query, tt.filter, 0, 10)
+			if ids := resultIDs(results); !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("expected IDs %v, got %v", tt.expectedIDs, ids)
+			}
+			if total != len(tt.expectedIDs) {
+				t.Errorf("expected total %v, got %v", len(tt.expectedIDs), total)
+			}
+			for _, result := range results {
+				if result.Match != MatchFuzzy || result.Score <= 0 || result.Score >= 1 {
+					t.Errorf("expected a fuzzy match with a score between 0 and 1, got %+v", result)
+				}
+			}
+		})
+	}
+
+	results, _ := idx.FuzzySearch("scarface", Filter{}, 0, 10)
+	if len(results) != 1 || results[0].Match != MatchExact || results[0].Score != 1 {
+		t.Errorf("expected an exact match with score 1, got %+v", results)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"godfather", "godfather", 0},
+		{"godfather", "godfahter", 1},
+		{"godfather", "godfathr", 1},
+		{"godfather", "godfatherr", 1},
+		{"godfather", "godmother", 2},
+		{"", "abc", 3},
+		{"amelie", "amélie", 1},
+	}
+	for _, tt := range tests {
+		if distance := editDistance([]rune(tt.a), []rune(tt.b)); distance != tt.expected {
+			t.Errorf("expected distance %v between %q and %q, got %v", tt.expected, tt.a, tt.b, distance)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	idx := buildTestIndex(t)
+	tests := []struct {
+		name     string
+		prefix   string
+		limit    int
+		expected []string
+	}{
+		{"most popular first", "godf", 10, []string{"tt0068646", "tt0071562", "tt0099674"}},
+		{"limit", "godf", 1, []string{"tt0068646"}},
+		{"whole word with trailing space", "star ", 10, []string{"tt0076759", "tt0092455", "tt0060028"}},
+		{"prefix of word", "star", 10, []string{"tt0076759", "tt0092455", "tt0060028", "tt10000000"}},
+		{"original title", "fabuleux", 10, []string{"tt0211915"}},
+		{"article", "the ", 10, nil},
+		{"no match", "xyz", 10, nil},
+		{"empty", "", 10, nil},
+		// More than maxSuggestScan entries start with "filler", so the suggestions are precomputed
+		{"precomputed", "filler", 3, []string{"tt2001500", "tt2001499", "tt2001498"}},
+		{"scanned", "filler 14", 3, []string{"tt2001499", "tt2001498", "tt2001497"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ids := idx.Suggest(tt.prefix, tt.limit); !reflect.DeepEqual(ids, tt.expected) && !(len(ids) == 0 && len(tt.expected) == 0) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	if ids := idx.Suggest("filler", 100); len(ids) != MaxSuggestions {
+		t.Errorf("expected the limit to be capped at %v, got %v suggestions", MaxSuggestions, len(ids))
+	}
+}
+
+func TestList(t *testing.T) {
+	idx := buildTestIndex(t)
+
+	t.Run("filter", func(t *testing.T) {
+		ids, more := idx.List(Filter{Genre: "crime"}, 0, 10)
+		expected := []string{"tt0068646", "tt0071562", "tt0086250", "tt0099674"}
+		if !reflect.DeepEqual(ids, expected) || more {
+			t.Errorf("expected %v without more titles, got %v (more: %v)", expected, ids, more)
+		}
+		ids, more = idx.List(Filter{TitleTypes: []pb.TitleType{pb.TitleType_SHORT}, StartYearFrom: 1800}, 0, 10)
+		expected = []string{"tt0000001", "tt1254207"}
+		if !reflect.DeepEqual(ids, expected) || more {
+			t.Errorf("expected %v without more titles, got %v (more: %v)", expected, ids, more)
+		}
+	})
+
+	t.Run("invalid or duplicate title types", func(t *testing.T) {
+		ids, more := idx.List(Filter{TitleTypes: []pb.TitleType{pb.TitleType_SHORT, -1, pb.TitleType_SHORT, 200}, StartYearFrom: 1800}, 0, 10)
+		expected := []string{"tt0000001", "tt1254207"}
+		if !reflect.DeepEqual(ids, expected) || more {
+			t.Errorf("expected %v without more titles, got %v (more: %v)", expected, ids, more)
+		}
+		if ids, _ = idx.List(Filter{TitleTypes: []pb.TitleType{-1}}, 0, 10); len(ids) != 0 {
+			t.Errorf("expected no titles for an invalid title type, got %v", ids)
+		}
+	})
+
+	// Paging with the last ID of each page as cursor must return all matching titles exactly once, in the order of their IDs
+	for _, filter := range []Filter{{}, {Genre: "Drama"}, {TitleTypes: []pb.TitleType{pb.TitleType_TV_EPISODE}, StartYearFrom: 1950, StartYearTo: 1959}} {
+		t.Run("cursor "+strconv.Itoa(len(filter.TitleTypes))+filter.Genre, func(t *testing.T) {
+			expectedTotal := idx.Facets(filter).Total
+			var all []string
+			var after uint32
+			for pages := 0; ; pages++ {
+				if pages > expectedTotal {
+					t.Fatal("too many pages")
+				}
+				ids, more := idx.List(filter, after, 7)
+				all = append(all, ids...)
+				if !more {
+					break
+				}
+				if len(ids) != 7 {
+					t.Fatalf("expected a full page before the last one, got %v", ids)
+				}
+				_, number, err := imdbid.Parse(ids[len(ids)-1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				after = number
+			}
+			if len(all) != expectedTotal {
+				t.Errorf("expected %v titles, got %v", expectedTotal, len(all))
+			}
+			seen := make(map[string]bool)
+			var previous uint32
+			for _, id := range all {
+				if seen[id] {
+					t.Errorf("title %v was listed twice", id)
+				}
+				seen[id] = true
+				_, number, _ := imdbid.Parse(id)
+				if number <= previous {
+					t.Errorf("title %v isn't in the order of the IDs", id)
+				}
+				previous = number
+			}
+		})
+	}
+
+	if ids, more := idx.List(Filter{}, 4000000000, 10); len(ids) != 0 || more {
+		t.Errorf("expected no titles after the last ID, got %v (more: %v)", ids, more)
+	}
+}
+
+func TestFacets(t *testing.T) {
+	idx := buildTestIndex(t)
+	facets := idx.Facets(Filter{})
+	if facets.Total != len(testTitles)+fillerCount || facets.Adult != 1 || facets.NonAdult != facets.Total-1 {
+		t.Errorf("unexpected totals: %+v", facets)
+	}
+	// Precomputed counts must be the same as counted ones
+	boolFalse := false
+	if counted := idx.Facets(Filter{Adult: &boolFalse}); counted.Total != facets.NonAdult {
+		t.Errorf("expected %v non-adult titles, got %v", facets.NonAdult, counted.Total)
+	}
+
+	facets = idx.Facets(Filter{Genre: "Crime"})
+	expected := Facets{
+		Total:      4,
+		TitleTypes: []TitleTypeCount{{TitleType: pb.TitleType_MOVIE, Count: 4}},
+		Genres:     []GenreCount{{Genre: "Crime", Count: 4}, {Genre: "Drama", Count: 4}},
+		Decades:    []DecadeCount{{Decade: 1970, Count: 2}, {Decade: 1980, Count: 1}, {Decade: 1990, Count: 1}},
+		NonAdult:   4,
+	}
+	if !reflect.DeepEqual(facets, expected) {
+		t.Errorf("expected %+v, got %+v", expected, facets)
+	}
+
+	// Duplicate title types must not count titles twice, and invalid ones don't match any title
+	facets = idx.Facets(Filter{TitleTypes: []pb.TitleType{pb.TitleType_MOVIE, -1}, Genre: "Crime"})
+	if duplicate := idx.Facets(Filter{TitleTypes: []pb.TitleType{pb.TitleType_MOVIE, pb.TitleType_MOVIE, -1}, Genre: "Crime"}); !reflect.DeepEqual(duplicate, facets) {
+		t.Errorf("expected %+v for duplicate title types, got %+v", facets, duplicate)
+	}
+	// Unlike the genre, the title types are the most selective secondary index here
+	facets = idx.Facets(Filter{TitleTypes: []pb.TitleType{pb.TitleType_SHORT, pb.TitleType_SHORT}})
+	if facets.Total != 3 || len(facets.TitleTypes) != 1 || facets.TitleTypes[0].Count != 3 {
+		t.Errorf("expected 3 shorts for duplicate title types, got %+v", facets)
+	}
+	if facets = idx.Facets(Filter{TitleTypes: []pb.TitleType{-1}}); facets.Total != 0 {
+		t.Errorf("expected no titles for an invalid title type, got %+v", facets)
+	}
+}
+
+func resultIDs(results []Result) []string {
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Letters that don't decompose into a base letter and a diacritic
+var specialLetters = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'ł': "l",
+	'đ': "d",
+	'ð': "d",
+	'þ': "th",
+	'ı': "i",
+}
+
+// Leading articles that are removed from titles with multiple words.
+// Only articles that aren't common words in English, so that for example "Die Hard" stays as it is.
+var articles = map[string]bool{
+	"the": true,
+	"a":   true,
+	"an":  true,
+	"le":  true,
+	"la":  true,
+	"les": true,
+	"el":  true,
+	"los": true,
+	"las": true,
+	"il":  true,
+	"gli": true,
+}
+
+// Normalize converts a title or query into the form that's used in the index:
+// Lowercase, without diacritics, without punctuation, with single spaces between words and without a leading article.
+// For example "The Lord of the Rings: The Fellowship of the Ring" becomes "lord of the rings the fellowship of the ring"
+// and "Le fabuleux destin d'Amélie Poulain" becomes "fabuleux destin damelie poulain".
+func Normalize(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	space := false
+	writeSpace := func() {
+		if sb.Len() > 0 {
+			space = true
+		}
+	}
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Diacritics from the decomposition
+		case r == '\'' || r == '’' || r == '`' || r == '´':
+			// "Schindler's List" -> "schindlers list"
+		case r == '&':
+			writeSpace()
+			sb.WriteString(" and")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space {
+				sb.WriteByte(' ')
+				space = false
+			}
+			r = unicode.ToLower(r)
+			if special, ok := specialLetters[r]; ok {
+				sb.WriteString(special)
+			} else {
+				sb.WriteRune(r)
+			}
+		default:
+			writeSpace()
+		}
+	}
+	normalized := strings.TrimPrefix(sb.String(), " ")
+
+	if i := strings.IndexByte(normalized, ' '); i > 0 && articles[normalized[:i]] {
+		normalized = normalized[i+1:]
+	}
+	return normalized
+}
@@ -0,0 +1,310 @@
+package index
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/deflix-tv/imdb2meta/pb"
+)
+
+// Result is a title that matches a search query.
+type Result struct {
+	ID        string
+	TitleType pb.TitleType
+	StartYear int
+	// Score is between 0 and 1, with 1 for an exact match of the normalized titles.
+	Score float64
+	Match MatchKind
+}
+
+// MatchKind describes how a title matches the query.
+type MatchKind int
+
+const (
+	// MatchWords means that the title contains all words of the query.
+	MatchWords MatchKind = iota
+	// MatchPrefix means that the title starts with the query.
+	MatchPrefix
+	// MatchExact means that the title is the same as the query.
+	MatchExact
+	// MatchFuzzy means that the title contains words that are similar to all words of the query.
+	MatchFuzzy
+)
+
+// Filter restricts the search results. All conditions must match, unset fields match all titles.
+type Filter struct {
+	// Matches titles with any of the title types
+	TitleTypes []pb.TitleType
+	// Inclusive. Titles without a start year don't match if StartYearFrom or StartYearTo are set.
+	StartYearFrom int
+	StartYearTo   int
+	// Case-insensitive
+	Genre string
+	// In minutes. Titles without a runtime don't match if it's set.
+	MinRuntime int
+	// Matches only adult or only non-adult titles if set
+	Adult *bool
+}
+
+// matcher returns a function that checks if a doc matches the filter.
+func (d *indexData) matcher(f Filter) func(doc uint32) bool {
+	var genreBit uint64
+	if f.Genre != "" {
+		for i, genre := range d.GenreNames {
+			if strings.EqualFold(genre, f.Genre) {
+				genreBit = 1 << i
+				break
+			}
+		}
+		// No title has an unknown genre
+		if genreBit == 0 {
+			return func(doc uint32) bool { return false }
+		}
+	}
+
+	return func(doc uint32) bool {
+		if len(f.TitleTypes) > 0 {
+			found := false
+			for _, t := range f.TitleTypes {
+				if uint8(t) == d.TitleTypes[doc] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		startYear := int(d.StartYears[doc])
+		if (f.StartYearFrom != 0 || f.StartYearTo != 0) && startYear == 0 {
+			return false
+		}
+		if f.StartYearFrom != 0 && startYear < f.StartYearFrom {
+			return false
+		}
+		if f.StartYearTo != 0 && startYear > f.StartYearTo {
+			return false
+		}
+		if genreBit != 0 && d.Genres[doc]&genreBit == 0 {
+			return false
+		}
+		if f.MinRuntime != 0 && int(d.Runtimes[doc]) < f.MinRuntime {
+			return false
+		}
+		if f.Adult != nil && d.Adult[doc] != *f.Adult {
+			return false
+		}
+		return true
+	}
+}
+
+const (
+	// minPrefixLength is the minimum number of bytes of the last query word for matching it as prefix.
+	// Shorter words only match whole words, because almost all titles contain a word with a prefix like "a".
+	minPrefixLength = 2
+	// maxMergedPostings limits the number of entries that are merged for a query word that matches multiple words of the index,
+	// like the prefix of the last word or the similar words of the fuzzy search, to keep the work per request bounded.
+	maxMergedPostings = 100000
+)
+
+// docScore is the best score of any entry of a doc.
+type docScore struct {
+	score float64
+	match MatchKind
+}
+
+// Search returns the titles that match the query and filter, ranked by how well they match, paginated with offset and limit.
+// It also returns the total number of matching titles.
+//
+// A title matches if its primary or original title contains all words of the query, with the last word also matching as prefix.
+// A last word with fewer than minPrefixLength bytes, or whose prefix matches words with more than maxMergedPostings entries, is only matched as prefix
+// among the titles that contain the other words of the query. Without other words it only matches whole words,
+// and if it's in more than maxMergedPostings entries, it only matches titles that are the word itself.
+func (idx *Index) Search(query string, filter Filter, offset, limit int) ([]Result, int) {
+	query = Normalize(query)
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return nil, 0
+	}
+
+	var entries []uint32
+	for i, word := range words {
+		var postings []uint32
+		if i < len(words)-1 {
+			postings = idx.wordPostings(word)
+		} else if start, end := idx.prefixWords(word); len(word) >= minPrefixLength && idx.postingCount(start, end) <= maxMergedPostings {
+			postings = idx.mergePostings(start, end)
+		} else if i > 0 {
+			// The other words limit the entries, so checking their titles is bounded, unlike merging the postings of all words with the prefix
+			entries = idx.data.withWordPrefix(entries, word)
+			break
+		} else if postings = idx.wordPostings(word); len(postings) > maxMergedPostings {
+			// Scoring all titles that contain a common word isn't bounded, so on its own the word only matches titles that are exactly the word
+			postings = idx.exactEntries(word)
+		}
+		if i == 0 {
+			entries = postings
+		} else {
+			entries = intersect(entries, postings)
+		}
+		if len(entries) == 0 {
+			return nil, 0
+		}
+	}
+	if len(entries) == 0 {
+		return nil, 0
+	}
+
+	// Score the entries and keep the best score per doc
+	matches := idx.data.matcher(filter)
+	scores := make(map[uint32]docScore, len(entries))
+	for _, entry := range entries {
+		doc := idx.data.EntryDocs[entry]
+		if !matches(doc) {
+			continue
+		}
+		if score, match := matchScore(query, idx.data.title(entry)); score > scores[doc].score {
+			scores[doc] = docScore{score: score, match: match}
+		}
+	}
+	return idx.page(scores, offset, limit)
+}
+
+// page ranks the scored docs by their score and popularity and returns the requested page of them, as well as the total number of docs.
+func (idx *Index) page(scores map[uint32]docScore, offset, limit int) ([]Result, int) {
+	docs := make([]uint32, 0, len(scores))
+	for doc := range scores {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if scoreI, scoreJ := scores[docs[i]].score, scores[docs[j]].score; scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		if popI, popJ := idx.data.popularity(docs[i]), idx.data.popularity(docs[j]); popI != popJ {
+			return popI > popJ
+		}
+		return idx.data.IDs[docs[i]] < idx.data.IDs[docs[j]]
+	})
+
+	total := len(docs)
+	if offset >= total {
+		return nil, total
+	}
+	docs = docs[offset:]
+	if len(docs) > limit {
+		docs = docs[:limit]
+	}
+	results := make([]Result, 0, len(docs))
+	for _, doc := range docs {
+		results = append(results, Result{
+			ID:        idx.id(doc),
+			TitleType: pb.TitleType(idx.data.TitleTypes[doc]),
+			StartYear: int(idx.data.StartYears[doc]),
+			Score:     scores[doc].score,
+			Match:     scores[doc].match,
+		})
+	}
+	return results, total
+}
+
+// matchScore rates how well a normalized title matches a normalized query whose words are all contained in the title.
+func matchScore(query, title string) (float64, MatchKind) {
+	if query == title {
+		return 1, MatchExact
+	}
+	// The share of the title that the query covers
+	coverage := float64(len(query)) / float64(len(title))
+	if coverage > 1 {
+		coverage = 1
+	}
+	if strings.HasPrefix(title, query) {
+		return 0.6 + 0.3*coverage, MatchPrefix
+	}
+	return 0.3 + 0.3*coverage, MatchWords
+}
+
+// wordPostings returns the sorted entries that contain the word.
+func (idx *Index) wordPostings(word string) []uint32 {
+	i := sort.SearchStrings(idx.data.Words, word)
+	if i == len(idx.data.Words) || idx.data.Words[i] != word {
+		return nil
+	}
+	return idx.data.Postings[idx.data.PostingOffsets[i]:idx.data.PostingOffsets[i+1]]
+}
+
+// prefixWords returns the range of words with the prefix.
+func (idx *Index) prefixWords(prefix string) (int, int) {
+	start := sort.SearchStrings(idx.data.Words, prefix)
+	end := start + sort.Search(len(idx.data.Words)-start, func(i int) bool {
+		return !strings.HasPrefix(idx.data.Words[start+i], prefix)
+	})
+	return start, end
+}
+
+// exactEntries returns the entries whose title is the query, but at most maxMergedPostings.
+func (idx *Index) exactEntries(query string) []uint32 {
+	lo, hi := idx.prefixRange(query)
+	var entries []uint32
+	// Titles that are the prefix itself are sorted first
+	for entry := lo; entry < hi && len(entries) < maxMergedPostings && string(idx.data.titleBytes(entry)) == query; entry++ {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// postingCount returns the number of postings of the words from start to end, without duplicates being removed.
+func (idx *Index) postingCount(start, end int) int {
+	return int(idx.data.PostingOffsets[end] - idx.data.PostingOffsets[start])
+}
+
+// mergePostings returns the sorted entries that contain any of the words from start to end.
+func (idx *Index) mergePostings(start, end int) []uint32 {
+	switch end - start {
+	case 0:
+		return nil
+	case 1:
+		return idx.data.Postings[idx.data.PostingOffsets[start]:idx.data.PostingOffsets[end]]
+	}
+	// The postings of the words are stored consecutively
+	postings := append([]uint32(nil), idx.data.Postings[idx.data.PostingOffsets[start]:idx.data.PostingOffsets[end]]...)
+	sort.Slice(postings, func(i, j int) bool { return postings[i] < postings[j] })
+	unique := postings[:0]
+	for i, entry := range postings {
+		if i == 0 || entry != postings[i-1] {
+			unique = append(unique, entry)
+		}
+	}
+	return unique
+}
+
+// withWordPrefix returns the entries whose title contains a word with the prefix.
+func (d *indexData) withWordPrefix(entries []uint32, prefix string) []uint32 {
+	var result []uint32
+	for _, entry := range entries {
+		for _, word := range strings.Fields(d.title(entry)) {
+			if strings.HasPrefix(word, prefix) {
+				result = append(result, entry)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// intersect returns the entries that are in both sorted slices.
+func intersect(a, b []uint32) []uint32 {
+	var result []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package index
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxSuggestions is the maximum number of suggestions for a prefix.
+	MaxSuggestions = 20
+	// maxSuggestScan is the maximum number of entries that are scanned for suggesting titles.
+	// For prefixes of more entries, the most popular docs are determined when building the index.
+	maxSuggestScan = 1000
+)
+
+// Suggest returns the IDs of the most popular titles whose primary or original title starts with the prefix, most popular first.
+// A prefix that ends with a space only matches titles that contain the prefix as whole word.
+func (idx *Index) Suggest(prefix string, limit int) []string {
+	normalized := Normalize(prefix)
+	if normalized == "" {
+		return nil
+	}
+	// Normalize removes trailing spaces, but "star " should only match "star wars", not "stargate".
+	// A leading article on its own isn't removed by Normalize, but it would be in the titles, so "the " must stay a prefix for words like "theory".
+	if strings.HasSuffix(prefix, " ") && !articles[normalized] {
+		normalized += " "
+	}
+	if limit > MaxSuggestions {
+		limit = MaxSuggestions
+	}
+
+	var docs []uint32
+	if i := sort.SearchStrings(idx.data.TopPrefixes, normalized); i < len(idx.data.TopPrefixes) && idx.data.TopPrefixes[i] == normalized {
+		docs = idx.data.TopDocs[idx.data.TopOffsets[i]:idx.data.TopOffsets[i+1]]
+	} else {
+		lo, hi := idx.prefixRange(normalized)
+		// There's no precomputed result for prefixes of fewer than maxSuggestScan entries
+		docs = idx.data.topDocs(lo, hi, limit)
+	}
+	if len(docs) > limit {
+		docs = docs[:limit]
+	}
+
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = idx.id(doc)
+	}
+	return ids
+}
+
+// prefixRange returns the range of entries whose title starts with the prefix.
+func (idx *Index) prefixRange(prefix string) (uint32, uint32) {
+	entryCount := len(idx.data.EntryDocs)
+	// The conversions of the titles to strings for comparing them don't allocate
+	lo := sort.Search(entryCount, func(i int) bool {
+		return string(idx.data.titleBytes(uint32(i))) >= prefix
+	})
+	hi := lo + sort.Search(entryCount-lo, func(i int) bool {
+		title := idx.data.titleBytes(uint32(lo + i))
+		return len(title) < len(prefix) || string(title[:len(prefix)]) != prefix
+	})
+	return uint32(lo), uint32(hi)
+}
+
+// topDocs returns the n most popular docs of the entries from lo to hi, most popular first.
+func (d *indexData) topDocs(lo, hi uint32, n int) []uint32 {
+	top := make([]uint32, 0, n+1)
+	for entry := lo; entry < hi; entry++ {
+		doc := d.EntryDocs[entry]
+		popularity := d.popularity(doc)
+		if len(top) == n && popularity <= d.popularity(top[n-1]) {
+			continue
+		}
+		// A doc can have two entries with the same prefix
+		duplicate := false
+		for _, other := range top {
+			if other == doc {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		// Insert sorted
+		i := sort.Search(len(top), func(i int) bool {
+			return d.popularity(top[i]) < popularity
+		})
+		top = append(top, 0)
+		copy(top[i+1:], top[i:])
+		top[i] = doc
+		if len(top) > n {
+			top = top[:n]
+		}
+	}
+	return top
+}
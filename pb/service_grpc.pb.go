@@ -18,6 +18,25 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MetaFetcherClient interface {
 	Get(ctx context.Context, in *MetaRequest, opts ...grpc.CallOption) (*Meta, error)
+	GetMany(ctx context.Context, in *MetasRequest, opts ...grpc.CallOption) (*MetasResponse, error)
+	// Streams the metas for a list of IDs or all metas that match a filter.
+	// For a list of IDs, IDs that aren't found are skipped.
+	StreamMetas(ctx context.Context, in *StreamMetasRequest, opts ...grpc.CallOption) (MetaFetcher_StreamMetasClient, error)
+	// Searches titles by their primary and original title.
+	// Requires the service to be started with a title index.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Resolves a release or file name like "Big.Buck.Bunny.2008.1080p.BluRay.x264.mkv" to the best matching titles.
+	// Requires the service to be started with a title index.
+	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
+	// Suggests the most popular titles that start with a prefix, for type-ahead.
+	// Requires the service to be started with a title index.
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error)
+	// Lists the titles that match a filter, in the order of their IDs, with cursor pagination.
+	// Requires the service to be started with a title index.
+	ListTitles(ctx context.Context, in *ListTitlesRequest, opts ...grpc.CallOption) (*ListTitlesResponse, error)
+	// Counts the titles that match a filter per title type, genre, decade and adult flag.
+	// Requires the service to be started with a title index.
+	GetFacets(ctx context.Context, in *FacetsRequest, opts ...grpc.CallOption) (*FacetsResponse, error)
 }
 
 type metaFetcherClient struct {
@@ -37,11 +56,116 @@ func (c *metaFetcherClient) Get(ctx context.Context, in *MetaRequest, opts ...gr
 	return out, nil
 }
 
+func (c *metaFetcherClient) GetMany(ctx context.Context, in *MetasRequest, opts ...grpc.CallOption) (*MetasResponse, error) {
+	out := new(MetasResponse)
+	err := c.cc.Invoke(ctx, "/imdb2meta.MetaFetcher/GetMany", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaFetcherClient) StreamMetas(ctx context.Context, in *StreamMetasRequest, opts ...grpc.CallOption) (MetaFetcher_StreamMetasClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MetaFetcher_ServiceDesc.Streams[0], "/imdb2meta.MetaFetcher/StreamMetas", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &metaFetcherStreamMetasClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MetaFetcher_StreamMetasClient interface {
+	Recv() (*Meta, error)
+	grpc.ClientStream
+}
+
+type metaFetcherStreamMetasClient struct {
+	grpc.ClientStream
+}
+
+func (x *metaFetcherStreamMetasClient) Recv() (*Meta, error) {
+	m := new(Meta)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *metaFetcherClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/imdb2meta.MetaFetcher/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaFetcherClient) Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, "/imdb2meta.MetaFetcher/Resolve", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaFetcherClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error) {
+	out := new(SuggestResponse)
+	err := c.cc.Invoke(ctx, "/imdb2meta.MetaFetcher/Suggest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaFetcherClient) ListTitles(ctx context.Context, in *ListTitlesRequest, opts ...grpc.CallOption) (*ListTitlesResponse, error) {
+	out := new(ListTitlesResponse)
+	err := c.cc.Invoke(ctx, "/imdb2meta.MetaFetcher/ListTitles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metaFetcherClient) GetFacets(ctx context.Context, in *FacetsRequest, opts ...grpc.CallOption) (*FacetsResponse, error) {
+	out := new(FacetsResponse)
+	err := c.cc.Invoke(ctx, "/imdb2meta.MetaFetcher/GetFacets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MetaFetcherServer is the server API for MetaFetcher service.
 // All implementations must embed UnimplementedMetaFetcherServer
 // for forward compatibility
 type MetaFetcherServer interface {
 	Get(context.Context, *MetaRequest) (*Meta, error)
+	GetMany(context.Context, *MetasRequest) (*MetasResponse, error)
+	// Streams the metas for a list of IDs or all metas that match a filter.
+	// For a list of IDs, IDs that aren't found are skipped.
+	StreamMetas(*StreamMetasRequest, MetaFetcher_StreamMetasServer) error
+	// Searches titles by their primary and original title.
+	// Requires the service to be started with a title index.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Resolves a release or file name like "Big.Buck.Bunny.2008.1080p.BluRay.x264.mkv" to the best matching titles.
+	// Requires the service to be started with a title index.
+	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	// Suggests the most popular titles that start with a prefix, for type-ahead.
+	// Requires the service to be started with a title index.
+	Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error)
+	// Lists the titles that match a filter, in the order of their IDs, with cursor pagination.
+	// Requires the service to be started with a title index.
+	ListTitles(context.Context, *ListTitlesRequest) (*ListTitlesResponse, error)
+	// Counts the titles that match a filter per title type, genre, decade and adult flag.
+	// Requires the service to be started with a title index.
+	GetFacets(context.Context, *FacetsRequest) (*FacetsResponse, error)
 	mustEmbedUnimplementedMetaFetcherServer()
 }
 
@@ -52,6 +176,27 @@ type UnimplementedMetaFetcherServer struct {
 func (UnimplementedMetaFetcherServer) Get(context.Context, *MetaRequest) (*Meta, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
+func (UnimplementedMetaFetcherServer) GetMany(context.Context, *MetasRequest) (*MetasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMany not implemented")
+}
+func (UnimplementedMetaFetcherServer) StreamMetas(*StreamMetasRequest, MetaFetcher_StreamMetasServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMetas not implemented")
+}
+func (UnimplementedMetaFetcherServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedMetaFetcherServer) Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedMetaFetcherServer) Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Suggest not implemented")
+}
+func (UnimplementedMetaFetcherServer) ListTitles(context.Context, *ListTitlesRequest) (*ListTitlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTitles not implemented")
+}
+func (UnimplementedMetaFetcherServer) GetFacets(context.Context, *FacetsRequest) (*FacetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFacets not implemented")
+}
 func (UnimplementedMetaFetcherServer) mustEmbedUnimplementedMetaFetcherServer() {}
 
 // UnsafeMetaFetcherServer may be embedded to opt out of forward compatibility for this service.
@@ -83,6 +228,135 @@ func _MetaFetcher_Get_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MetaFetcher_GetMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaFetcherServer).GetMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/imdb2meta.MetaFetcher/GetMany",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaFetcherServer).GetMany(ctx, req.(*MetasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaFetcher_StreamMetas_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMetasRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetaFetcherServer).StreamMetas(m, &metaFetcherStreamMetasServer{stream})
+}
+
+type MetaFetcher_StreamMetasServer interface {
+	Send(*Meta) error
+	grpc.ServerStream
+}
+
+type metaFetcherStreamMetasServer struct {
+	grpc.ServerStream
+}
+
+func (x *metaFetcherStreamMetasServer) Send(m *Meta) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MetaFetcher_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaFetcherServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/imdb2meta.MetaFetcher/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaFetcherServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaFetcher_Resolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaFetcherServer).Resolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/imdb2meta.MetaFetcher/Resolve",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaFetcherServer).Resolve(ctx, req.(*ResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaFetcher_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaFetcherServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/imdb2meta.MetaFetcher/Suggest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaFetcherServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaFetcher_ListTitles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTitlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaFetcherServer).ListTitles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/imdb2meta.MetaFetcher/ListTitles",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaFetcherServer).ListTitles(ctx, req.(*ListTitlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetaFetcher_GetFacets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FacetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetaFetcherServer).GetFacets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/imdb2meta.MetaFetcher/GetFacets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetaFetcherServer).GetFacets(ctx, req.(*FacetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MetaFetcher_ServiceDesc is the grpc.ServiceDesc for MetaFetcher service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -94,7 +368,37 @@ var MetaFetcher_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Get",
 			Handler:    _MetaFetcher_Get_Handler,
 		},
+		{
+			MethodName: "GetMany",
+			Handler:    _MetaFetcher_GetMany_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _MetaFetcher_Search_Handler,
+		},
+		{
+			MethodName: "Resolve",
+			Handler:    _MetaFetcher_Resolve_Handler,
+		},
+		{
+			MethodName: "Suggest",
+			Handler:    _MetaFetcher_Suggest_Handler,
+		},
+		{
+			MethodName: "ListTitles",
+			Handler:    _MetaFetcher_ListTitles_Handler,
+		},
+		{
+			MethodName: "GetFacets",
+			Handler:    _MetaFetcher_GetFacets_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMetas",
+			Handler:       _MetaFetcher_StreamMetas_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }
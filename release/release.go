@@ -0,0 +1,154 @@
+// Package release parses release and file names of movies and TV shows, like "Big.Buck.Bunny.2008.1080p.BluRay.x264.mkv",
+// into the title and hints like the year and season/episode.
+package release
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info is the information that could be parsed from a release name.
+type Info struct {
+	Title string
+	// Year is 0 if the name doesn't contain a year.
+	Year int
+	// Season and Episode are 0 if the name doesn't contain them.
+	// A season pack only has the Season.
+	Season  int
+	Episode int
+}
+
+// IsEpisodic returns true if the name contains a season or episode, which means it's a TV show and not a movie.
+func (i Info) IsEpisodic() bool {
+	return i.Season > 0 || i.Episode > 0
+}
+
+// File extensions that are removed from the name.
+// Other extensions can't be distinguished from the last word of a title like in "Dr.No".
+var extensions = map[string]bool{
+	".mkv":     true,
+	".mp4":     true,
+	".m4v":     true,
+	".avi":     true,
+	".mov":     true,
+	".wmv":     true,
+	".mpg":     true,
+	".mpeg":    true,
+	".ts":      true,
+	".webm":    true,
+	".flv":     true,
+	".ogm":     true,
+	".srt":     true,
+	".sub":     true,
+	".idx":     true,
+	".nfo":     true,
+	".torrent": true,
+}
+
+// Words that are part of the release info after the title, like the video resolution, source and codec.
+// They're compared in lowercase.
+var tags = map[string]bool{
+	"480p": true, "576p": true, "720p": true, "1080p": true, "1080i": true, "2160p": true, "4k": true, "uhd": true,
+	"bluray": true, "blu-ray": true, "bdrip": true, "brrip": true, "bdremux": true, "remux": true,
+	"web-dl": true, "webdl": true, "webrip": true, "hdtv": true, "pdtv": true, "hdrip": true,
+	"dvdrip": true, "dvdscr": true, "dvd": true, "dvd5": true, "dvd9": true, "hdcam": true, "telesync": true,
+	"x264": true, "x265": true, "h264": true, "h265": true, "hevc": true, "avc": true, "xvid": true, "divx": true,
+	"aac": true, "ac3": true, "dts": true, "ddp5": true, "dd5": true, "truehd": true, "flac": true,
+	"hdr": true, "hdr10": true, "10bit": true, "8bit": true, "repack": true,
+}
+
+// Release tags that are also common words in titles, like in "A Real Pain" or "Charlotte's Web".
+// They're only recognized as tags after the release year.
+var wordTags = map[string]bool{
+	"web": true, "cam": true, "ts": true, "atmos": true, "dv": true,
+	"proper": true, "real": true, "internal": true, "limited": true, "extended": true, "unrated": true,
+	"uncut": true, "remastered": true, "directors": true, "dc": true, "imax": true,
+	"multi": true, "dubbed": true, "subbed": true, "complete": true,
+}
+
+var (
+	// "S01E02", "S01E02E03", "S01" (season pack)
+	seasonEpisodeRegex = regexp.MustCompile(`^s(\d{1,2})(?:e(\d{1,3}))?(?:e\d{1,3})*$`)
+	// "1x02"
+	xEpisodeRegex = regexp.MustCompile(`^(\d{1,2})x(\d{2,3})$`)
+	// "2008", "(2008)", "[2008]"
+	yearRegex = regexp.MustCompile(`^[(\[]?((?:18|19|20)\d\d)[)\]]?$`)
+	// Characters that are used instead of spaces in release names
+	separatorReplacer = strings.NewReplacer(".", " ", "_", " ")
+)
+
+// Parse parses a release or file name. Directories in the path are ignored.
+// The title is everything before the year, season/episode or the first release tag like "1080p".
+// The first word is always part of the title, like in "Real Steel", and tags that are also common words only end the title after the year.
+// If no title is found, for example because the name starts with the season/episode, the title is empty.
+func Parse(name string) Info {
+	name = strings.ReplaceAll(name, `\`, "/")
+	name = path.Base(name)
+	if ext := path.Ext(name); extensions[strings.ToLower(ext)] {
+		name = strings.TrimSuffix(name, ext)
+	}
+	// Keep the dots in codec names like "H.264"
+	name = strings.NewReplacer("H.264", "H264", "h.264", "h264", "H.265", "H265", "h.265", "h265").Replace(name)
+	words := strings.Fields(separatorReplacer.Replace(name))
+	// Names of anime releases often start with the release group in brackets
+	if len(words) > 0 && strings.HasPrefix(words[0], "[") && strings.HasSuffix(words[0], "]") {
+		words = words[1:]
+	}
+
+	var info Info
+	// Index of the first word after the title
+	end := len(words)
+	// Index of the last year in the title part and its value, because the title itself can contain or be a year, like in "2001: A Space Odyssey" or "1917"
+	yearIndex, year := -1, 0
+	maxYear := time.Now().Year() + 2
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		if m := seasonEpisodeRegex.FindStringSubmatch(lower); m != nil {
+			info.Season, _ = strconv.Atoi(m[1])
+			info.Episode, _ = strconv.Atoi(m[2])
+			end = i
+			break
+		}
+		if m := xEpisodeRegex.FindStringSubmatch(lower); m != nil {
+			info.Season, _ = strconv.Atoi(m[1])
+			info.Episode, _ = strconv.Atoi(m[2])
+			end = i
+			break
+		}
+		if lower == "season" && i+1 < len(words) {
+			if season, err := strconv.Atoi(words[i+1]); err == nil {
+				info.Season = season
+				end = i
+				break
+			}
+		}
+		if i > 0 && (isTag(tags, lower) || yearIndex > 0 && isTag(wordTags, lower)) {
+			end = i
+			break
+		}
+		if m := yearRegex.FindStringSubmatch(word); m != nil {
+			if y, _ := strconv.Atoi(m[1]); y <= maxYear {
+				yearIndex, year = i, y
+			}
+		}
+	}
+	// The year is only the release year if it's not the first word, otherwise it's part of the title
+	if yearIndex > 0 {
+		info.Year = year
+		// Words between the year and the first tag, like the language, aren't part of the title
+		end = yearIndex
+	}
+
+	title := strings.Join(words[:end], " ")
+	// Separators like in "Title - 01" or "Title (2008)"
+	info.Title = strings.TrimRight(title, " -([")
+	return info
+}
+
+// isTag returns true if the lowercase word is one of the tags, also in brackets like "[1080p]".
+func isTag(set map[string]bool, word string) bool {
+	return set[word] || set[strings.Trim(word, "[]()")]
+}
@@ -0,0 +1,60 @@
+package release
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Info
+	}{
+		{"Big.Buck.Bunny.2008.1080p.BluRay.x264.mkv", Info{Title: "Big Buck Bunny", Year: 2008}},
+		{"Big.Buck.Bunny.1080p.BluRay.x264.mkv", Info{Title: "Big Buck Bunny"}},
+		{"Big Buck Bunny (2008) [1080p].mp4", Info{Title: "Big Buck Bunny", Year: 2008}},
+		{"/downloads/movies/Big_Buck_Bunny_2008.avi", Info{Title: "Big Buck Bunny", Year: 2008}},
+		{`C:\Downloads\Big.Buck.Bunny.2008.mkv`, Info{Title: "Big Buck Bunny", Year: 2008}},
+		{"Big.Buck.Bunny.2008.GERMAN.DL.1080p.mkv", Info{Title: "Big Buck Bunny", Year: 2008}},
+		{"Movie.2019.H.264.mkv", Info{Title: "Movie", Year: 2019}},
+		// Tags that are also words in titles
+		{"Real.Steel.2011.1080p.BluRay.x264.mkv", Info{Title: "Real Steel", Year: 2011}},
+		{"A.Real.Pain.2024.1080p.WEB.mkv", Info{Title: "A Real Pain", Year: 2024}},
+		{"Cam.2018.1080p.mkv", Info{Title: "Cam", Year: 2018}},
+		{"Charlottes.Web.2006.1080p.mkv", Info{Title: "Charlottes Web", Year: 2006}},
+		{"The.Complete.Works.2020.mkv", Info{Title: "The Complete Works", Year: 2020}},
+		{"Limited.Partners.2019.LIMITED.DVDRip.mkv", Info{Title: "Limited Partners", Year: 2019}},
+		{"Some.Movie.2019.REAL.PROPER.1080p.mkv", Info{Title: "Some Movie", Year: 2019}},
+		{"Some.Movie.REAL.PROPER.mkv", Info{Title: "Some Movie REAL PROPER"}},
+		// Titles that contain or are a year
+		{"2001.A.Space.Odyssey.1968.1080p.mkv", Info{Title: "2001 A Space Odyssey", Year: 1968}},
+		{"1917.2019.1080p.mkv", Info{Title: "1917", Year: 2019}},
+		{"1917.mkv", Info{Title: "1917"}},
+		{"Blade.Runner.2049.2017.2160p.mkv", Info{Title: "Blade Runner 2049", Year: 2017}},
+		// TV shows
+		{"Big.Buck.Bunny.S01E02.720p.HDTV.x264.mkv", Info{Title: "Big Buck Bunny", Season: 1, Episode: 2}},
+		{"Big.Buck.Bunny.S01E02E03.mkv", Info{Title: "Big Buck Bunny", Season: 1, Episode: 2}},
+		{"Big.Buck.Bunny.S02.COMPLETE.720p.mkv", Info{Title: "Big Buck Bunny", Season: 2}},
+		{"Big.Buck.Bunny.1x02.mkv", Info{Title: "Big Buck Bunny", Season: 1, Episode: 2}},
+		{"Big Buck Bunny Season 3", Info{Title: "Big Buck Bunny", Season: 3}},
+		{"Big.Buck.Bunny.2019.S01E02.mkv", Info{Title: "Big Buck Bunny", Year: 2019, Season: 1, Episode: 2}},
+		{"Real.Humans.S01E01.mkv", Info{Title: "Real Humans", Season: 1, Episode: 1}},
+		{"[Group] Big Buck Bunny - 01x02 [1080p].mkv", Info{Title: "Big Buck Bunny", Season: 1, Episode: 2}},
+		// Without title
+		{"S01E02.mkv", Info{Season: 1, Episode: 2}},
+		{"", Info{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if info := Parse(tt.name); info != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, info)
+			}
+		})
+	}
+}
+
+func TestIsEpisodic(t *testing.T) {
+	if (Info{Title: "Big Buck Bunny", Year: 2008}).IsEpisodic() {
+		t.Error("expected a movie not to be episodic")
+	}
+	if !(Info{Title: "Big Buck Bunny", Season: 1}).IsEpisodic() {
+		t.Error("expected a season pack to be episodic")
+	}
+}
@@ -0,0 +1,158 @@
+package staticdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"time"
+)
+
+// DB is an opened static DB file.
+// It's safe for concurrent use.
+type DB struct {
+	data   []byte
+	header header
+	seeds  []byte
+	slots  []byte
+	values []byte
+
+	metadata map[string][]byte
+}
+
+// Open memory-maps the static DB file at the given path and verifies its header and checksum.
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := mmapFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't memory-map file: %w", err)
+	}
+
+	db, err := newDB(data)
+	if err != nil {
+		_ = munmap(data)
+		return nil, err
+	}
+	return db, nil
+}
+
+func newDB(data []byte) (*DB, error) {
+	h, err := unmarshalHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.version != FormatVersion {
+		return nil, fmt.Errorf("unsupported format version %v, expected %v", h.version, FormatVersion)
+	}
+	// The checksum doesn't cover the header, so the section sizes are checked against the file size,
+	// in a way that corrupted counts can't overflow. unmarshalHeader already checked that the file contains the header.
+	size := uint64(len(data)) - headerSize
+	if h.count == 0 || h.bucketCount == 0 || h.bucketCount > size/4 || h.count > (size-4*h.bucketCount)/8 {
+		return nil, ErrInvalidFile
+	}
+	slotsStart := headerSize + 4*h.bucketCount
+	valuesStart := slotsStart + 8*h.count
+	if crc32.Checksum(data[headerSize:], crcTable) != h.checksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	db := &DB{
+		data:     data,
+		header:   h,
+		seeds:    data[headerSize:slotsStart],
+		slots:    data[slotsStart:valuesStart],
+		values:   data[valuesStart:],
+		metadata: make(map[string][]byte),
+	}
+	if h.metaOffset != 0 {
+		if h.metaOffset < valuesStart || h.metaOffset >= uint64(len(data)) {
+			return nil, ErrInvalidFile
+		}
+		db.values = data[valuesStart:h.metaOffset]
+		meta := data[h.metaOffset:]
+		count, n := binary.Uvarint(meta)
+		if n <= 0 {
+			return nil, ErrInvalidFile
+		}
+		meta = meta[n:]
+		for i := uint64(0); i < count; i++ {
+			var key, value []byte
+			var ok bool
+			if key, value, meta, ok = readEntry(meta); !ok {
+				return nil, ErrInvalidFile
+			}
+			db.metadata[string(key)] = value
+		}
+	}
+	return db, nil
+}
+
+// Get returns the value for the given key, or false if the key doesn't exist.
+// It doesn't allocate. The returned slice points into the memory-mapped file, so it must not be modified and must not be used after closing the DB.
+func (db *DB) Get(key string) ([]byte, bool) {
+	h := hash(key)
+	b := h % db.header.bucketCount
+	seed := binary.LittleEndian.Uint32(db.seeds[4*b:])
+	s := slot(h, seed, db.header.count)
+	offset := binary.LittleEndian.Uint64(db.slots[8*s:])
+	// Offsets can only be out of bounds when header fields are corrupted, which the checksum doesn't cover
+	if offset >= uint64(len(db.values)) {
+		return nil, false
+	}
+
+	// The perfect hash function maps unknown keys to arbitrary slots, so the key must be compared
+	entryKey, value, _, ok := readEntry(db.values[offset:])
+	if !ok || string(entryKey) != key {
+		return nil, false
+	}
+	return value, true
+}
+
+// ForEach calls fn for each key-value pair, in the order they were added to the Writer.
+// It returns ErrInvalidFile if the value section ends before all pairs were read, which is only possible with corrupted header fields.
+// The slices point into the memory-mapped file, so they must not be modified and must not be used after closing the DB.
+func (db *DB) ForEach(fn func(key, value []byte) error) error {
+	entries := db.values
+	for i := uint64(0); i < db.header.count; i++ {
+		key, value, rest, ok := readEntry(entries)
+		if !ok {
+			return ErrInvalidFile
+		}
+		entries = rest
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Metadata returns the value of a key-value pair from the metadata section, or false if the key doesn't exist.
+// The returned slice must not be modified and must not be used after closing the DB.
+func (db *DB) Metadata(key string) ([]byte, bool) {
+	value, found := db.metadata[key]
+	return value, found
+}
+
+// Len returns the number of key-value pairs.
+func (db *DB) Len() int {
+	return int(db.header.count)
+}
+
+// Version returns the format version of the file.
+func (db *DB) Version() int {
+	return int(db.header.version)
+}
+
+// DatasetDate returns the date of the dataset the file was created from.
+func (db *DB) DatasetDate() time.Time {
+	return db.header.datasetDate
+}
+
+// Close unmaps the file. Slices returned by Get must not be used afterwards.
+func (db *DB) Close() error {
+	return munmap(db.data)
+}
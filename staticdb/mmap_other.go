@@ -0,0 +1,15 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package staticdb
+
+import "os"
+
+// mmapFile reads the whole file into memory on platforms where we don't use mmap.
+func mmapFile(f *os.File) ([]byte, error) {
+	return os.ReadFile(f.Name())
+}
+
+func munmap(data []byte) error {
+	return nil
+}
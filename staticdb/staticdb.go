@@ -0,0 +1,134 @@
+// Package staticdb implements an immutable, memory-mapped key-value file format for read-only serving.
+//
+// A file consists of a fixed size header, a minimal perfect hash function (MPHF) over all keys and a packed value section:
+//
+//	header   | magic (8) | format version (4) | checksum (4) | dataset date (8) | count (8) | bucket count (8) | metadata offset (8) | reserved (16) |
+//	seeds    | bucket count * uint32
+//	slots    | count * uint64 (offset of the slot's entry in the value section)
+//	values   | count * (uvarint key length | key | uvarint value length | value)
+//	metadata | uvarint count | count * (uvarint key length | key | uvarint value length | value)
+//
+// All integers are little-endian. The checksum is a CRC-32C over everything following the header. The header fields are validated against the file size instead.
+// The metadata section is optional and contains arbitrary key-value pairs, like the encoding of the keys. Its offset is relative to the start of the file.
+// It's 0 in files without metadata.
+//
+// The MPHF is built with the "hash, displace and compress" (CHD) approach, without the compression:
+// Keys are distributed into buckets by their hash and for each bucket a seed is searched for that maps all of the bucket's keys to free slots.
+// A lookup then only requires hashing the key once, reading the bucket's seed and the slot's offset and comparing the stored key.
+package staticdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"time"
+)
+
+// FormatVersion is the version of the file format that's written and can be read by this package.
+const FormatVersion = 1
+
+const (
+	headerSize = 64
+	// Average number of keys per bucket. Higher values lead to a smaller file, but take longer to build.
+	bucketSize = 4
+)
+
+var (
+	magic = [8]byte{'I', 'M', 'D', 'B', '2', 'M', 'P', 'H'}
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+var (
+	// ErrInvalidFile is returned when opening a file that's not in the expected format.
+	ErrInvalidFile = errors.New("not a valid static DB file")
+	// ErrChecksumMismatch is returned when opening a file whose content doesn't match the checksum in its header.
+	ErrChecksumMismatch = errors.New("checksum mismatch, the static DB file is corrupted")
+)
+
+type header struct {
+	version     uint32
+	checksum    uint32
+	datasetDate time.Time
+	count       uint64
+	bucketCount uint64
+	metaOffset  uint64
+}
+
+func (h header) marshal() []byte {
+	b := make([]byte, headerSize)
+	copy(b, magic[:])
+	binary.LittleEndian.PutUint32(b[8:], h.version)
+	binary.LittleEndian.PutUint32(b[12:], h.checksum)
+	binary.LittleEndian.PutUint64(b[16:], uint64(h.datasetDate.Unix()))
+	binary.LittleEndian.PutUint64(b[24:], h.count)
+	binary.LittleEndian.PutUint64(b[32:], h.bucketCount)
+	binary.LittleEndian.PutUint64(b[40:], h.metaOffset)
+	return b
+}
+
+func unmarshalHeader(b []byte) (header, error) {
+	if len(b) < headerSize || string(b[:8]) != string(magic[:]) {
+		return header{}, ErrInvalidFile
+	}
+	return header{
+		version:     binary.LittleEndian.Uint32(b[8:]),
+		checksum:    binary.LittleEndian.Uint32(b[12:]),
+		datasetDate: time.Unix(int64(binary.LittleEndian.Uint64(b[16:])), 0).UTC(),
+		count:       binary.LittleEndian.Uint64(b[24:]),
+		bucketCount: binary.LittleEndian.Uint64(b[32:]),
+		metaOffset:  binary.LittleEndian.Uint64(b[40:]),
+	}, nil
+}
+
+// hash is FNV-1a with a final avalanche step. It's unseeded, the bucket's seed is only applied to the hash in slot.
+// It's implemented here instead of using hash/fnv to avoid allocations on lookups.
+func hash(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+	return mix(h)
+}
+
+// appendEntry appends a key-value pair in the format of the value and metadata sections.
+func appendEntry(dst []byte, key string, value []byte) []byte {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(key)))
+	dst = append(dst, lenBuf[:n]...)
+	dst = append(dst, key...)
+	n = binary.PutUvarint(lenBuf[:], uint64(len(value)))
+	dst = append(dst, lenBuf[:n]...)
+	return append(dst, value...)
+}
+
+// readEntry reads a key-value pair in the format of the value and metadata sections and returns the remaining data.
+// It returns false if the data doesn't start with a complete entry.
+func readEntry(data []byte) (key, value, rest []byte, ok bool) {
+	keyLen, n := binary.Uvarint(data)
+	if n <= 0 || keyLen > uint64(len(data)-n) {
+		return nil, nil, nil, false
+	}
+	key, data = data[n:uint64(n)+keyLen], data[uint64(n)+keyLen:]
+	valLen, n := binary.Uvarint(data)
+	if n <= 0 || valLen > uint64(len(data)-n) {
+		return nil, nil, nil, false
+	}
+	return key, data[n : uint64(n)+valLen], data[uint64(n)+valLen:], true
+}
+
+// slot maps a key's hash to a slot, using the seed of the key's bucket.
+func slot(h uint64, seed uint32, count uint64) uint64 {
+	return mix(h^(uint64(seed)*0x9e3779b97f4a7c15)) % count
+}
+
+// mix is the finalizer of SplitMix64.
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,225 @@
+package staticdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	datasetDate := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	w := NewWriter()
+	var keys []string
+	for i := 0; i < 1000; i++ {
+		key := "tt" + strconv.Itoa(1000000+i)
+		keys = append(keys, key)
+		w.Add(key, []byte("value of "+key))
+	}
+	// Empty values must be distinguishable from missing keys
+	keys = append(keys, "tt0")
+	w.Add("tt0", nil)
+	w.SetMetadata("keyEncoding", []byte("string"))
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := w.WriteFile(path, datasetDate); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if db.Len() != len(keys) {
+		t.Errorf("expected %v key-value pairs, got %v", len(keys), db.Len())
+	}
+	if db.Version() != FormatVersion {
+		t.Errorf("expected format version %v, got %v", FormatVersion, db.Version())
+	}
+	if !db.DatasetDate().Equal(datasetDate) {
+		t.Errorf("expected dataset date %v, got %v", datasetDate, db.DatasetDate())
+	}
+	for _, key := range keys {
+		value, found := db.Get(key)
+		if !found {
+			t.Errorf("key %v not found", key)
+			continue
+		}
+		expected := []byte("value of " + key)
+		if key == "tt0" {
+			expected = []byte{}
+		}
+		if !bytes.Equal(value, expected) {
+			t.Errorf("expected value %q for key %v, got %q", expected, key, value)
+		}
+	}
+	for _, key := range []string{"", "tt1", "tt999999", "tt1000000 ", "tt10000000", "foo"} {
+		if value, found := db.Get(key); found {
+			t.Errorf("expected key %q not to be found, got value %q", key, value)
+		}
+	}
+
+	value, found := db.Metadata("keyEncoding")
+	if !found || string(value) != "string" {
+		t.Errorf("expected metadata value \"string\", got %q (found: %v)", value, found)
+	}
+	if _, found = db.Metadata("foo"); found {
+		t.Error("expected missing metadata key not to be found")
+	}
+
+	i := 0
+	err = db.ForEach(func(key, value []byte) error {
+		if string(key) != keys[i] {
+			t.Errorf("expected key %v at position %v, got %s", keys[i], i, key)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != len(keys) {
+		t.Errorf("expected ForEach to iterate over %v key-value pairs, got %v", len(keys), i)
+	}
+	errStop := errors.New("stop")
+	if err = db.ForEach(func(key, value []byte) error { return errStop }); err != errStop {
+		t.Errorf("expected ForEach to return the callback's error, got %v", err)
+	}
+}
+
+func TestWithoutMetadata(t *testing.T) {
+	w := NewWriter()
+	w.Add("tt1254207", []byte("Big Buck Bunny"))
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := w.WriteFile(path, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if value, found := db.Get("tt1254207"); !found || string(value) != "Big Buck Bunny" {
+		t.Errorf("expected value \"Big Buck Bunny\", got %q (found: %v)", value, found)
+	}
+	if _, found := db.Metadata("keyEncoding"); found {
+		t.Error("expected no metadata")
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := NewWriter().WriteFile(path, time.Time{}); err == nil {
+		t.Error("expected an error for a file without key-value pairs")
+	}
+	w := NewWriter()
+	w.Add("tt1254207", []byte("a"))
+	w.Add("tt1254207", []byte("b"))
+	if err := w.WriteFile(path, time.Time{}); err == nil {
+		t.Error("expected an error for duplicate keys")
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	w := NewWriter()
+	for i := 0; i < 100; i++ {
+		w.Add("tt"+strconv.Itoa(i), []byte("value"))
+	}
+	w.SetMetadata("keyEncoding", []byte("string"))
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.db")
+	if err := w.WriteFile(path, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(data []byte) []byte
+		expected error
+	}{
+		{"corrupted seed", func(data []byte) []byte { data[headerSize] ^= 0xff; return data }, ErrChecksumMismatch},
+		{"corrupted value", func(data []byte) []byte { data[len(data)/2] ^= 0x01; return data }, ErrChecksumMismatch},
+		{"corrupted metadata", func(data []byte) []byte { data[len(data)-1] ^= 0x01; return data }, ErrChecksumMismatch},
+		{"truncated", func(data []byte) []byte { return data[:len(data)-1] }, ErrChecksumMismatch},
+		{"truncated header", func(data []byte) []byte { return data[:headerSize-1] }, ErrInvalidFile},
+		{"truncated slots", func(data []byte) []byte { return data[:headerSize+8] }, ErrInvalidFile},
+		{"wrong magic", func(data []byte) []byte { data[0] = 'X'; return data }, ErrInvalidFile},
+		{"empty", func(data []byte) []byte { return nil }, ErrInvalidFile},
+		// The checksum doesn't cover the header
+		{"zero bucket count", func(data []byte) []byte { binary.LittleEndian.PutUint64(data[32:], 0); return data }, ErrInvalidFile},
+		{"overflowing bucket count", func(data []byte) []byte { binary.LittleEndian.PutUint64(data[32:], 1<<62); return data }, ErrInvalidFile},
+		{"overflowing count", func(data []byte) []byte { binary.LittleEndian.PutUint64(data[24:], 1<<61); return data }, ErrInvalidFile},
+		{"count exceeding file", func(data []byte) []byte { binary.LittleEndian.PutUint64(data[24:], uint64(len(data))/8); return data }, ErrInvalidFile},
+		{"metadata offset before values", func(data []byte) []byte { binary.LittleEndian.PutUint64(data[40:], headerSize); return data }, ErrInvalidFile},
+		{"metadata offset after file", func(data []byte) []byte { binary.LittleEndian.PutUint64(data[40:], uint64(len(data))); return data }, ErrInvalidFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := tt.modify(append([]byte(nil), data...))
+			path := filepath.Join(dir, "modified.db")
+			if err := os.WriteFile(path, modified, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			db, err := Open(path)
+			if err == nil {
+				db.Close()
+			}
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected error %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// Header fields that are in bounds, but don't match the sections, must not lead to panics
+	for _, field := range []struct {
+		name   string
+		offset int
+		values []uint64
+	}{
+		{"count", 24, []uint64{1, 50, 99, 101}},
+		{"bucket count", 32, []uint64{1, 2, 100}},
+		{"metadata offset", 40, []uint64{0, 100, 1000, 1500}},
+	} {
+		for _, value := range field.values {
+			t.Run(field.name+" "+strconv.FormatUint(value, 10), func(t *testing.T) {
+				modified := append([]byte(nil), data...)
+				binary.LittleEndian.PutUint64(modified[field.offset:], value)
+				path := filepath.Join(dir, "modified.db")
+				if err := os.WriteFile(path, modified, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				db, err := Open(path)
+				if err != nil {
+					return
+				}
+				defer db.Close()
+				for i := 0; i < 100; i++ {
+					db.Get("tt" + strconv.Itoa(i))
+				}
+				_ = db.ForEach(func(key, value []byte) error { return nil })
+			})
+		}
+	}
+
+	t.Run("unsupported version", func(t *testing.T) {
+		modified := append([]byte(nil), data...)
+		modified[8] = FormatVersion + 1
+		path := filepath.Join(dir, "modified.db")
+		if err := os.WriteFile(path, modified, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if db, err := Open(path); err == nil {
+			db.Close()
+			t.Error("expected an error for an unsupported format version")
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package staticdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Writer collects key-value pairs and writes them as static DB file.
+// All pairs are kept in memory until the file is written, because the perfect hash function can only be built when all keys are known.
+type Writer struct {
+	// Keys and values are stored in contiguous slices to avoid millions of small allocations
+	data    []byte
+	offsets []uint64 // Start of the i-th entry in data
+	hashes  []uint64
+
+	metadata map[string][]byte
+}
+
+// NewWriter creates a new Writer.
+func NewWriter() *Writer {
+	return &Writer{
+		metadata: make(map[string][]byte),
+	}
+}
+
+// Add adds a key-value pair. Keys must be unique.
+func (w *Writer) Add(key string, value []byte) {
+	w.offsets = append(w.offsets, uint64(len(w.data)))
+	w.hashes = append(w.hashes, hash(key))
+	w.data = appendEntry(w.data, key, value)
+}
+
+// SetMetadata sets a key-value pair that's stored in the metadata section of the file instead of the indexed value section.
+func (w *Writer) SetMetadata(key string, value []byte) {
+	w.metadata[key] = value
+}
+
+// Len returns the number of added key-value pairs.
+func (w *Writer) Len() int {
+	return len(w.offsets)
+}
+
+// WriteFile builds the perfect hash function and writes all key-value pairs to the file at the given path.
+// The dataset date is stored in the file header, so that clients can find out how old the data is.
+func (w *Writer) WriteFile(path string, datasetDate time.Time) error {
+	count := uint64(len(w.offsets))
+	if count == 0 {
+		return errors.New("no key-value pairs were added")
+	}
+	seeds, slots, err := w.build()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The header contains the checksum, so we write it last
+	if _, err = f.Seek(headerSize, io.SeekStart); err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(f, 1<<20)
+	crc := crc32.New(crcTable)
+	out := io.MultiWriter(bw, crc)
+	buf := make([]byte, 8)
+	for _, seed := range seeds {
+		binary.LittleEndian.PutUint32(buf, seed)
+		if _, err = out.Write(buf[:4]); err != nil {
+			return err
+		}
+	}
+	for _, entry := range slots {
+		binary.LittleEndian.PutUint64(buf, w.offsets[entry])
+		if _, err = out.Write(buf); err != nil {
+			return err
+		}
+	}
+	if _, err = out.Write(w.data); err != nil {
+		return err
+	}
+	var metaOffset uint64
+	if len(w.metadata) > 0 {
+		metaOffset = headerSize + 4*uint64(len(seeds)) + 8*count + uint64(len(w.data))
+		// Sorted for reproducible files
+		keys := make([]string, 0, len(w.metadata))
+		for key := range w.metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		meta := make([]byte, binary.MaxVarintLen64)
+		meta = meta[:binary.PutUvarint(meta, uint64(len(keys)))]
+		for _, key := range keys {
+			meta = appendEntry(meta, key, w.metadata[key])
+		}
+		if _, err = out.Write(meta); err != nil {
+			return err
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+
+	h := header{
+		version:     FormatVersion,
+		checksum:    crc.Sum32(),
+		datasetDate: datasetDate,
+		count:       count,
+		bucketCount: uint64(len(seeds)),
+		metaOffset:  metaOffset,
+	}
+	if _, err = f.WriteAt(h.marshal(), 0); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// build searches a seed for each bucket and returns the seeds and which entry is stored in which slot.
+func (w *Writer) build() ([]uint32, []int, error) {
+	count := uint64(len(w.hashes))
+
+	// Keys with the same hash can never be mapped to different slots, so we check that upfront instead of searching for a seed forever
+	sortedHashes := append([]uint64(nil), w.hashes...)
+	sort.Slice(sortedHashes, func(i, j int) bool { return sortedHashes[i] < sortedHashes[j] })
+	for i := 1; i < len(sortedHashes); i++ {
+		if sortedHashes[i] == sortedHashes[i-1] {
+			return nil, nil, errors.New("duplicate key or hash collision")
+		}
+	}
+
+	bucketCount := (count + bucketSize - 1) / bucketSize
+	buckets := make([][]int, bucketCount)
+	for i, h := range w.hashes {
+		b := h % bucketCount
+		buckets[b] = append(buckets[b], i)
+	}
+	// Buckets with many keys are the hardest to place, so they're placed first while most slots are still free
+	order := make([]int, bucketCount)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(buckets[order[i]]) > len(buckets[order[j]]) })
+
+	seeds := make([]uint32, bucketCount)
+	slots := make([]int, count)
+	for i := range slots {
+		slots[i] = -1
+	}
+	var bucketSlots []uint64
+	for _, b := range order {
+		bucket := buckets[b]
+		if len(bucket) == 0 {
+			break
+		}
+		found := false
+		for seed := uint32(0); seed < 1<<31 && !found; seed++ {
+			bucketSlots = bucketSlots[:0]
+			found = true
+			for _, entry := range bucket {
+				s := slot(w.hashes[entry], seed, count)
+				if slots[s] != -1 || containsSlot(bucketSlots, s) {
+					found = false
+					break
+				}
+				bucketSlots = append(bucketSlots, s)
+			}
+			if found {
+				seeds[b] = seed
+				for j, entry := range bucket {
+					slots[bucketSlots[j]] = entry
+				}
+			}
+		}
+		if !found {
+			return nil, nil, errors.New("couldn't find a seed for a bucket")
+		}
+	}
+
+	return seeds, slots, nil
+}
+
+func containsSlot(slots []uint64, s uint64) bool {
+	for _, other := range slots {
+		if other == s {
+			return true
+		}
+	}
+	return false
+}